@@ -17,30 +17,106 @@ limitations under the License.
 package v1alpha1
 
 import (
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // AuthorizationModelSpec defines the desired state of AuthorizationModel
+// +kubebuilder:validation:XValidation:rule="has(self.schema) != has(self.dsl)",message="exactly one of schema or dsl must be set"
 type AuthorizationModelSpec struct {
 	// StoreRef is a reference to the OpenFGA store where this model will be applied
 	// +kubebuilder:validation:Required
 	StoreRef StoreReference `json:"storeRef"`
 
-	// Schema defines the authorization model schema
-	// +kubebuilder:validation:Required
-	Schema AuthorizationSchema `json:"schema"`
+	// Schema defines the authorization model as structured type definitions.
+	// Exactly one of Schema or DSL must be set; the controller transpiles
+	// DSL into Schema before validating and applying the model, and writes
+	// the transpiled result back to Status.CompiledSchema once it is applied
+	Schema AuthorizationSchema `json:"schema,omitempty"`
+
+	// DSL defines the authorization model using OpenFGA's native modeling
+	// language, e.g.:
+	//   model
+	//     schema 1.1
+	//   type user
+	//   type document
+	//     relations
+	//       define owner: [user]
+	//       define viewer: [user] or owner
+	// Exactly one of Schema or DSL must be set
+	DSL string `json:"dsl,omitempty"`
 
 	// SchemaVersion specifies the version of the authorization model schema
 	// +kubebuilder:validation:Pattern="^1\\.1$"
 	// +kubebuilder:default="1.1"
 	SchemaVersion string `json:"schemaVersion,omitempty"`
 
-	// Conditions defines additional conditions for the authorization model
-	Conditions map[string]string `json:"conditions,omitempty"`
+	// RollbackTo, when set, makes the controller re-apply a previously
+	// applied model version from Status.History instead of compiling
+	// Schema/DSL. Every rollback still creates a brand-new, immutable
+	// OpenFGA authorization model; OpenFGA has no in-place model mutation
+	RollbackTo *ModelVersionReference `json:"rollbackTo,omitempty"`
+
+	// HistoryLimit bounds how many entries Status.History retains. Oldest
+	// entries are pruned first; they remain valid and queryable in OpenFGA,
+	// only the operator's local record of them is dropped
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=25
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
+
+	// PinnedModelID, when set, pins Status.ModelID to this exact OpenFGA
+	// model ID instead of whatever RolloutPolicy would otherwise select -
+	// e.g. to freeze a store on a known-good version while a new Schema/DSL
+	// is staged, or to promote a version written under RolloutPolicy Manual
+	// or Canary once it's been validated out of band. The ID must already
+	// exist in Status.History or the pin is rejected
+	PinnedModelID string `json:"pinnedModelID,omitempty"`
+
+	// RolloutPolicy controls how a newly compiled/rolled-back model version
+	// becomes Status.ModelID. "Immediate" promotes it as soon as it's
+	// written to the store. "Manual" and "Canary" still write and record
+	// the version in Status.History, but leave Status.ModelID on its
+	// current value until PinnedModelID names that version explicitly -
+	// OpenFGA has no server-side traffic splitting, so "Canary" in practice
+	// means other resources (e.g. an AuthorizationQuery pinned to the new
+	// ModelID via AuthorizationModelReference) can exercise the new version
+	// before it's promoted
+	// +kubebuilder:validation:Enum=Immediate;Manual;Canary
+	// +kubebuilder:default="Immediate"
+	RolloutPolicy string `json:"rolloutPolicy,omitempty"`
 
 	// OpenTelemetry configuration for observability
 	OpenTelemetry *OpenTelemetryConfig `json:"openTelemetry,omitempty"`
+
+	// DriftDetection enables periodic Expand-based comparison of the
+	// deployed model against Schema/DSL
+	DriftDetection *DriftDetectionConfig `json:"driftDetection,omitempty"`
+}
+
+// DriftDetectionConfig controls continuous drift detection for an AuthorizationModel
+type DriftDetectionConfig struct {
+	// Enabled indicates whether drift detection is enabled
+	// +kubebuilder:default=false
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Interval between Expand-based drift checks
+	// +kubebuilder:default="5m"
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Remediation selects what happens when drift is detected. "Report"
+	// only updates Status.DriftReport and the NoDrift condition. "Reapply"
+	// additionally re-writes the CRD's compiled model back to the store
+	// +kubebuilder:validation:Enum=Report;Reapply
+	// +kubebuilder:default="Report"
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// ModelVersionReference identifies one entry in Status.History to roll back to
+type ModelVersionReference struct {
+	// Version is the operator-assigned, monotonically increasing version number to roll back to
+	Version int64 `json:"version,omitempty"`
+
+	// ModelID is the OpenFGA model ID to roll back to, as an alternative to Version
+	ModelID string `json:"modelID,omitempty"`
 }
 
 // StoreReference defines a reference to an OpenFGA store
@@ -77,6 +153,44 @@ type AuthorizationSchema struct {
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinItems=1
 	TypeDefinitions []TypeDefinition `json:"type_definitions"`
+
+	// Conditions define named ABAC conditions that relations can reference by
+	// name via RelationReference.Condition. Keyed by condition name so two
+	// conditions can never collide on a name the way a []Condition with an
+	// internal Name field could
+	Conditions map[string]Condition `json:"conditions,omitempty"`
+}
+
+// Condition defines a named ABAC condition evaluated with Google Common
+// Expression Language (CEL) against a relation's typed parameters and the
+// context supplied on a Check/Write request. Type-checked by
+// internal/abac.ValidateConditions in the reconciler before the model is
+// pushed to OpenFGA; a malformed Expression fails the resource rather than
+// the store
+type Condition struct {
+	// Expression is the CEL expression evaluated against Parameters, e.g.
+	// "request_time < grant_expiry"
+	// +kubebuilder:validation:Required
+	Expression string `json:"expression"`
+
+	// Parameters declares the name and type of each variable the Expression may reference
+	Parameters map[string]ConditionParamType `json:"parameters,omitempty"`
+
+	// Metadata provides additional information about the condition
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// ConditionParamType declares the OpenFGA condition parameter type used to
+// type-check a Condition's CEL expression
+type ConditionParamType struct {
+	// TypeName is the base parameter type
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=string;bool;int;uint;double;duration;timestamp;ipaddress;list;map;any
+	TypeName string `json:"typeName"`
+
+	// GenericTypes declares the element type(s) for "list" (one entry) and
+	// "map" (one entry, the value type; keys are always string) TypeName values
+	GenericTypes []ConditionParamType `json:"genericTypes,omitempty"`
 }
 
 // TypeDefinition defines a type and its relations in the authorization model
@@ -197,6 +311,10 @@ type AuthorizationModelStatus struct {
 	// StoreID is the ID of the store where the model is deployed
 	StoreID string `json:"storeID,omitempty"`
 
+	// ConditionsCount is the number of entries in Schema.Conditions for the
+	// currently applied model, surfaced as a printcolumn for `kubectl get`
+	ConditionsCount int32 `json:"conditionsCount,omitempty"`
+
 	// ObservedGeneration reflects the generation of the most recently observed AuthorizationModel
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
@@ -206,17 +324,98 @@ type AuthorizationModelStatus struct {
 	// ValidationErrors contains any validation errors from OpenFGA
 	ValidationErrors []string `json:"validationErrors,omitempty"`
 
+	// DSLParseErrors contains any syntax errors found while transpiling Spec.DSL to Schema
+	DSLParseErrors []string `json:"dslParseErrors,omitempty"`
+
+	// CompiledSchema is the AuthorizationSchema actually applied to OpenFGA:
+	// Spec.Schema verbatim, or the result of transpiling Spec.DSL
+	CompiledSchema *AuthorizationSchema `json:"compiledSchema,omitempty"`
+
+	// CurrentVersion is the Version of the most recently written History
+	// entry; it grows by one on every model write. Under a RolloutPolicy other
+	// than Immediate, or with PinnedModelID set, Status.ModelID can name an
+	// earlier version
+	CurrentVersion int64 `json:"currentVersion,omitempty"`
+
+	// History records every OpenFGA model version this resource has ever
+	// applied, oldest first and bounded by Spec.HistoryLimit. Entries are
+	// never mutated once appended, mirroring OpenFGA's own immutable models
+	History []ModelVersionRecord `json:"history,omitempty"`
+
+	// DriftReport holds the result of the most recent Expand-based drift
+	// check, when Spec.DriftDetection is enabled. A "NoDrift" condition in
+	// Conditions mirrors DriftReport.Drifted for easy alerting
+	DriftReport *DriftReport `json:"driftReport,omitempty"`
+
 	// AppliedAt is the timestamp when the model was successfully applied
 	AppliedAt *metav1.Time `json:"appliedAt,omitempty"`
 }
 
+// ModelVersionRecord is one immutable entry in AuthorizationModelStatus.History
+type ModelVersionRecord struct {
+	// Version is the operator-assigned, monotonically increasing version number
+	Version int64 `json:"version"`
+
+	// ModelID is the OpenFGA-assigned ID for this model version
+	ModelID string `json:"modelID"`
+
+	// SchemaVersion is the OpenFGA schema version (e.g. "1.1") this version was applied with
+	SchemaVersion string `json:"schemaVersion,omitempty"`
+
+	// Checksum is a content hash of the compiled schema, used to detect no-op applies
+	Checksum string `json:"checksum,omitempty"`
+
+	// AppliedAt is the timestamp when this version was applied to OpenFGA
+	AppliedAt *metav1.Time `json:"appliedAt,omitempty"`
+
+	// RolledBackFrom, when set, is the Version this entry was a rollback away from
+	RolledBackFrom *int64 `json:"rolledBackFrom,omitempty"`
+}
+
+// DriftReport summarizes the result of comparing live Expand() responses
+// against the userset tree shape implied by Spec.Schema.TypeDefinitions
+type DriftReport struct {
+	// CheckedAt is when this drift check ran
+	CheckedAt *metav1.Time `json:"checkedAt,omitempty"`
+
+	// Drifted indicates whether any type#relation pair mismatched
+	Drifted bool `json:"drifted"`
+
+	// Mismatches lists the type#relation pairs whose live shape differs
+	// from the locally defined shape. Empty when Drifted is false
+	Mismatches []RelationDrift `json:"mismatches,omitempty"`
+}
+
+// RelationDrift describes a single type#relation pair whose live Expand()
+// userset tree shape no longer matches its local Relation definition
+type RelationDrift struct {
+	// Type is the OpenFGA type name
+	Type string `json:"type"`
+
+	// Relation is the relation name within Type
+	Relation string `json:"relation"`
+
+	// ExpectedNodeType is the userset tree node type (Leaf, Union,
+	// Intersection, Difference or TupleToUserset) implied by the local Relation,
+	// or "Undefined" when the pair is not defined locally
+	ExpectedNodeType string `json:"expectedNodeType"`
+
+	// ObservedNodeType is the userset tree node type OpenFGA's Expand call returned,
+	// or "Missing"/"ExpandFailed" when the pair has no live result or its Expand call failed
+	ObservedNodeType string `json:"observedNodeType"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Version",type="integer",JSONPath=".status.currentVersion"
 // +kubebuilder:printcolumn:name="Model ID",type="string",JSONPath=".status.modelID"
 // +kubebuilder:printcolumn:name="Store ID",type="string",JSONPath=".status.storeID"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="Applied At",type="date",JSONPath=".status.appliedAt"
+// +kubebuilder:printcolumn:name="Conditions",type="integer",JSONPath=".status.conditionsCount"
+// +kubebuilder:printcolumn:name="Pinned",type="string",JSONPath=".spec.pinnedModelID"
+// +kubebuilder:printcolumn:name="Drifted",type="boolean",JSONPath=".status.driftReport.drifted"
 
 // AuthorizationModel is the Schema for the authorizationmodels API
 type AuthorizationModel struct {
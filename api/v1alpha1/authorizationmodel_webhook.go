@@ -0,0 +1,89 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// authorizationModelValidator implements the mutual-exclusivity check
+// between Spec.Schema and Spec.DSL that the XValidation CEL rule on
+// AuthorizationModelSpec already enforces for API-server-side validation.
+// It exists as a belt-and-suspenders webhook for clients (e.g. `kubectl
+// --dry-run=none` against an older CRD revision, or any client bypassing
+// structural-schema validation) that would otherwise reach the reconciler,
+// which does not check the conflict: it transpiles DSL whenever DSL is set
+// and ignores Schema
+type authorizationModelValidator struct{}
+
+// +kubebuilder:webhook:path=/validate-openfga-authcore-io-v1alpha1-authorizationmodel,mutating=false,failurePolicy=fail,sideEffects=None,groups=openfga.authcore.io,resources=authorizationmodels,verbs=create;update,versions=v1alpha1,name=vauthorizationmodel.kb.io,admissionReviewVersions=v1
+
+func (v *authorizationModelValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	model, ok := obj.(*AuthorizationModel)
+	if !ok {
+		return nil, fmt.Errorf("expected an AuthorizationModel but got %T", obj)
+	}
+	return nil, validateAuthorizationModelSpec(model)
+}
+
+func (v *authorizationModelValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	model, ok := newObj.(*AuthorizationModel)
+	if !ok {
+		return nil, fmt.Errorf("expected an AuthorizationModel but got %T", newObj)
+	}
+	return nil, validateAuthorizationModelSpec(model)
+}
+
+func (v *authorizationModelValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateAuthorizationModelSpec rejects a spec that sets both or neither of
+// Schema/DSL, mirroring the CEL rule on AuthorizationModelSpec
+func validateAuthorizationModelSpec(model *AuthorizationModel) error {
+	hasSchema := len(model.Spec.Schema.TypeDefinitions) > 0 || len(model.Spec.Schema.Conditions) > 0
+	hasDSL := model.Spec.DSL != ""
+
+	if hasSchema == hasDSL {
+		fieldErr := field.Invalid(field.NewPath("spec"), model.Spec, "exactly one of schema or dsl must be set")
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: "AuthorizationModel"},
+			model.Name,
+			field.ErrorList{fieldErr},
+		)
+	}
+	return nil
+}
+
+// SetupWebhookWithManager registers the validating webhook for AuthorizationModel
+func (in *AuthorizationModel) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(in).
+		WithValidator(&authorizationModelValidator{}).
+		Complete()
+}
+
+var _ webhook.CustomValidator = &authorizationModelValidator{}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateAuthorizationModelSpecRejectsNeitherSet(t *testing.T) {
+	model := &AuthorizationModel{ObjectMeta: metav1.ObjectMeta{Name: "model"}}
+	if err := validateAuthorizationModelSpec(model); err == nil {
+		t.Fatalf("expected an error when neither schema nor dsl is set")
+	}
+}
+
+func TestValidateAuthorizationModelSpecRejectsBothSet(t *testing.T) {
+	model := &AuthorizationModel{
+		ObjectMeta: metav1.ObjectMeta{Name: "model"},
+		Spec: AuthorizationModelSpec{
+			Schema: AuthorizationSchema{TypeDefinitions: []TypeDefinition{{Type: "user"}}},
+			DSL:    "type user\n",
+		},
+	}
+	if err := validateAuthorizationModelSpec(model); err == nil {
+		t.Fatalf("expected an error when both schema and dsl are set")
+	}
+}
+
+func TestValidateAuthorizationModelSpecAcceptsSchemaOnly(t *testing.T) {
+	model := &AuthorizationModel{
+		ObjectMeta: metav1.ObjectMeta{Name: "model"},
+		Spec: AuthorizationModelSpec{
+			Schema: AuthorizationSchema{TypeDefinitions: []TypeDefinition{{Type: "user"}}},
+		},
+	}
+	if err := validateAuthorizationModelSpec(model); err != nil {
+		t.Fatalf("expected schema-only spec to be accepted, got %v", err)
+	}
+}
+
+func TestValidateAuthorizationModelSpecAcceptsDSLOnly(t *testing.T) {
+	model := &AuthorizationModel{
+		ObjectMeta: metav1.ObjectMeta{Name: "model"},
+		Spec:       AuthorizationModelSpec{DSL: "type user\n"},
+	}
+	if err := validateAuthorizationModelSpec(model); err != nil {
+		t.Fatalf("expected dsl-only spec to be accepted, got %v", err)
+	}
+}
+
+func TestAuthorizationModelValidatorImplementsCustomValidator(t *testing.T) {
+	v := &authorizationModelValidator{}
+	model := &AuthorizationModel{Spec: AuthorizationModelSpec{DSL: "type user\n"}}
+
+	if _, err := v.ValidateCreate(context.Background(), model); err != nil {
+		t.Fatalf("ValidateCreate returned error: %v", err)
+	}
+	if _, err := v.ValidateUpdate(context.Background(), model, model); err != nil {
+		t.Fatalf("ValidateUpdate returned error: %v", err)
+	}
+	if _, err := v.ValidateDelete(context.Background(), model); err != nil {
+		t.Fatalf("ValidateDelete returned error: %v", err)
+	}
+}
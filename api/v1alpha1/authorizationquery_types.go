@@ -0,0 +1,245 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AuthorizationQuerySpec defines the desired state of AuthorizationQuery. It
+// declaratively asserts the answer OpenFGA should give for a Check, Expand,
+// ListObjects or ListUsers call, so the result can be continuously
+// reconciled and surfaced as a Condition (e.g. for CI gating or drift
+// alerting) instead of invoked imperatively from a test script.
+type AuthorizationQuerySpec struct {
+	// StoreRef is a reference to the OpenFGA store to query
+	// +kubebuilder:validation:Required
+	StoreRef StoreReference `json:"storeRef"`
+
+	// AuthorizationModelRef pins the query to a specific AuthorizationModel.
+	// If omitted, the store's latest applied model is used
+	AuthorizationModelRef *AuthorizationModelReference `json:"authorizationModelRef,omitempty"`
+
+	// Type selects which OpenFGA API the query invokes
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=Check;Expand;ListObjects;ListUsers
+	Type string `json:"type"`
+
+	// Check holds the request and expected response for a Check query. Required when Type is "Check"
+	Check *CheckQuery `json:"check,omitempty"`
+
+	// Expand holds the request and expected response for an Expand query. Required when Type is "Expand"
+	Expand *ExpandQuery `json:"expand,omitempty"`
+
+	// ListObjects holds the request and expected response for a ListObjects query. Required when Type is "ListObjects"
+	ListObjects *ListObjectsQuery `json:"listObjects,omitempty"`
+
+	// ListUsers holds the request and expected response for a ListUsers query. Required when Type is "ListUsers"
+	ListUsers *ListUsersQuery `json:"listUsers,omitempty"`
+
+	// Schedule holds a cron expression for re-evaluating the query. The
+	// reconciler does not read it and does not requeue a query on a timer:
+	// the query is evaluated each time the object is reconciled, e.g. after
+	// its spec changes
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// AuthorizationModelReference pins a query to one authorization model version
+type AuthorizationModelReference struct {
+	// Name is the name of the AuthorizationModel resource
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the AuthorizationModel resource. Defaults to the referencing resource's namespace
+	Namespace string `json:"namespace,omitempty"`
+
+	// ModelID pins the exact OpenFGA model ID, bypassing Name/Namespace resolution
+	ModelID string `json:"modelID,omitempty"`
+}
+
+// CheckQuery asserts whether a user has a relation to an object
+type CheckQuery struct {
+	// User is the subject of the check, e.g. "user:anne"
+	// +kubebuilder:validation:Required
+	User string `json:"user"`
+
+	// Relation is the relation being checked, e.g. "viewer"
+	// +kubebuilder:validation:Required
+	Relation string `json:"relation"`
+
+	// Object is the target object, e.g. "document:roadmap"
+	// +kubebuilder:validation:Required
+	Object string `json:"object"`
+
+	// Context supplies values referenced by ABAC Condition expressions
+	Context map[string]string `json:"context,omitempty"`
+
+	// ContextualTuples are additional tuples for this check only, not persisted to the store.
+	// The reconciler does not send them: a Check query that sets ContextualTuples fails without being evaluated
+	ContextualTuples []ContextualTuple `json:"contextualTuples,omitempty"`
+
+	// ExpectAllowed is the expected Check result
+	// +kubebuilder:validation:Required
+	ExpectAllowed bool `json:"expectAllowed"`
+}
+
+// ContextualTuple is a tuple supplied inline with a query instead of being persisted to the store
+type ContextualTuple struct {
+	// User is the tuple's subject, e.g. "user:anne"
+	// +kubebuilder:validation:Required
+	User string `json:"user"`
+
+	// Relation is the tuple's relation
+	// +kubebuilder:validation:Required
+	Relation string `json:"relation"`
+
+	// Object is the tuple's object
+	// +kubebuilder:validation:Required
+	Object string `json:"object"`
+}
+
+// ExpandQuery asserts the userset tree OpenFGA returns for a relation on an object
+type ExpandQuery struct {
+	// Relation is the relation to expand, e.g. "viewer"
+	// +kubebuilder:validation:Required
+	Relation string `json:"relation"`
+
+	// Object is the target object, e.g. "document:roadmap"
+	// +kubebuilder:validation:Required
+	Object string `json:"object"`
+
+	// ExpectLeaves lists the leaf users/usersets the expansion is expected to contain
+	ExpectLeaves []string `json:"expectLeaves,omitempty"`
+}
+
+// ListObjectsQuery asserts the set of objects a user has a relation to
+type ListObjectsQuery struct {
+	// User is the subject, e.g. "user:anne"
+	// +kubebuilder:validation:Required
+	User string `json:"user"`
+
+	// Relation is the relation being listed, e.g. "viewer"
+	// +kubebuilder:validation:Required
+	Relation string `json:"relation"`
+
+	// Type restricts results to objects of this type, e.g. "document"
+	// +kubebuilder:validation:Required
+	Type string `json:"type"`
+
+	// Context supplies values referenced by ABAC Condition expressions.
+	// The reconciler does not send it: a ListObjects query that sets Context fails without being evaluated
+	Context map[string]string `json:"context,omitempty"`
+
+	// ExpectObjects lists the objects the query is expected to return, order-independent
+	ExpectObjects []string `json:"expectObjects,omitempty"`
+}
+
+// ListUsersQuery asserts the set of users who have a relation to an object
+type ListUsersQuery struct {
+	// Object is the target object, e.g. "document:roadmap"
+	// +kubebuilder:validation:Required
+	Object string `json:"object"`
+
+	// Relation is the relation being listed, e.g. "viewer"
+	// +kubebuilder:validation:Required
+	Relation string `json:"relation"`
+
+	// UserFilters restricts results to these user types, e.g. ["user", "team#member"]
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	UserFilters []string `json:"userFilters"`
+
+	// ExpectUsers lists the users the query is expected to return, order-independent
+	ExpectUsers []string `json:"expectUsers,omitempty"`
+}
+
+// AuthorizationQueryStatus defines the observed state of AuthorizationQuery
+type AuthorizationQueryStatus struct {
+	// Conditions represent the latest available observations of the query's
+	// current state, including a "Satisfied" condition reflecting whether
+	// the most recent evaluation matched the expected result
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Phase represents the current phase of the query
+	// +kubebuilder:validation:Enum=Pending;Evaluated;Failed;Unknown
+	Phase string `json:"phase,omitempty"`
+
+	// LastEvaluationTime is the last time the query was evaluated against OpenFGA
+	LastEvaluationTime *metav1.Time `json:"lastEvaluationTime,omitempty"`
+
+	// ActualResult is a human-readable rendering of the result OpenFGA returned
+	ActualResult string `json:"actualResult,omitempty"`
+
+	// Result holds the typed result of the most recent evaluation, populated
+	// according to Spec.Type: Allowed for Check, Leaves for Expand, Objects
+	// for ListObjects, or Users for ListUsers. ActualResult remains the
+	// human-readable rendering of the same data for quick kubectl inspection
+	Result *QueryResult `json:"result,omitempty"`
+
+	// ResultHash is a content hash of Result, letting callers - e.g. a
+	// drift-alerting integration - detect that the evaluated result actually
+	// changed without deep-comparing Result itself
+	ResultHash string `json:"resultHash,omitempty"`
+
+	// Satisfied indicates whether ActualResult matched the expectation in Spec
+	Satisfied *bool `json:"satisfied,omitempty"`
+}
+
+// QueryResult is the typed result of evaluating an AuthorizationQuery.
+// Exactly one field is populated, matching Spec.Type
+type QueryResult struct {
+	// Allowed is the Check result
+	Allowed *bool `json:"allowed,omitempty"`
+
+	// Leaves lists the leaf users/usersets an Expand call returned, order-independent
+	Leaves []string `json:"leaves,omitempty"`
+
+	// Objects lists the objects a ListObjects call returned, order-independent
+	Objects []string `json:"objects,omitempty"`
+
+	// Users lists the users a ListUsers call returned, order-independent
+	Users []string `json:"users,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Satisfied",type="boolean",JSONPath=".status.satisfied"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// AuthorizationQuery is the Schema for the authorizationqueries API
+type AuthorizationQuery struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AuthorizationQuerySpec   `json:"spec,omitempty"`
+	Status AuthorizationQueryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AuthorizationQueryList contains a list of AuthorizationQuery
+type AuthorizationQueryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AuthorizationQuery `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AuthorizationQuery{}, &AuthorizationQueryList{})
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupTargetSpec defines the desired state of BackupTarget
+type BackupTargetSpec struct {
+	// Destination selects where backups produced against this target are written
+	// +kubebuilder:validation:Required
+	Destination BackupDestination `json:"destination"`
+}
+
+// BackupDestination defines a pluggable backup storage destination
+type BackupDestination struct {
+	// Type selects which destination fields apply
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=pvc;s3;gcs;azureBlob
+	Type string `json:"type"`
+
+	// PVC configures a PersistentVolumeClaim-backed destination
+	PVC *PVCBackupDestination `json:"pvc,omitempty"`
+
+	// S3 configures an S3-compatible object storage destination
+	S3 *S3BackupDestination `json:"s3,omitempty"`
+
+	// GCS configures a Google Cloud Storage destination
+	GCS *GCSBackupDestination `json:"gcs,omitempty"`
+
+	// AzureBlob configures an Azure Blob Storage destination
+	AzureBlob *AzureBlobBackupDestination `json:"azureBlob,omitempty"`
+}
+
+// PVCBackupDestination defines a PersistentVolumeClaim-backed backup destination
+type PVCBackupDestination struct {
+	// ClaimName is the name of the PersistentVolumeClaim to write backups to
+	// +kubebuilder:validation:Required
+	ClaimName string `json:"claimName"`
+
+	// Path is the directory where backups are written, taken as a path in the
+	// operator pod's filesystem. The operator does not mount ClaimName itself
+	// +kubebuilder:default="/"
+	Path string `json:"path,omitempty"`
+}
+
+// S3BackupDestination defines an S3-compatible object storage backup destination
+type S3BackupDestination struct {
+	// Endpoint is the S3-compatible API endpoint. Leave empty to use the AWS default for Region
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Bucket is the destination bucket name
+	// +kubebuilder:validation:Required
+	Bucket string `json:"bucket"`
+
+	// Prefix is prepended to object keys written under this destination
+	Prefix string `json:"prefix,omitempty"`
+
+	// Region is the AWS region (or region-compatible value for S3-compatible providers)
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecret references the secret containing the access key ID and secret access key
+	CredentialsSecret *corev1.SecretReference `json:"credentialsSecret,omitempty"`
+
+	// PathStyle requests path-style addressing instead of virtual-hosted-style.
+	// The S3 writer does not read it: it uses path-style addressing whenever
+	// Endpoint is set and virtual-hosted-style otherwise
+	// +kubebuilder:default=false
+	PathStyle *bool `json:"pathStyle,omitempty"`
+}
+
+// GCSBackupDestination defines a Google Cloud Storage backup destination
+type GCSBackupDestination struct {
+	// Bucket is the destination bucket name
+	// +kubebuilder:validation:Required
+	Bucket string `json:"bucket"`
+
+	// Prefix is prepended to object names written under this destination
+	Prefix string `json:"prefix,omitempty"`
+
+	// CredentialsSecret references the secret containing the GCS service account key JSON
+	CredentialsSecret *corev1.SecretReference `json:"credentialsSecret,omitempty"`
+}
+
+// AzureBlobBackupDestination defines an Azure Blob Storage backup destination
+type AzureBlobBackupDestination struct {
+	// AccountName is the Azure storage account name
+	// +kubebuilder:validation:Required
+	AccountName string `json:"accountName"`
+
+	// Container is the destination blob container name
+	// +kubebuilder:validation:Required
+	Container string `json:"container"`
+
+	// Prefix is prepended to blob names written under this destination
+	Prefix string `json:"prefix,omitempty"`
+
+	// CredentialsSecret references the secret containing the account key or SAS token
+	CredentialsSecret *corev1.SecretReference `json:"credentialsSecret,omitempty"`
+}
+
+// BackupTargetStatus defines the observed state of BackupTarget
+type BackupTargetStatus struct {
+	// Conditions represent the latest available observations of the target's current state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Phase represents the current phase of the backup target
+	// +kubebuilder:validation:Enum=Pending;Ready;Failed;Unknown
+	Phase string `json:"phase,omitempty"`
+
+	// LastValidatedTime is the last time connectivity to the destination was verified
+	LastValidatedTime *metav1.Time `json:"lastValidatedTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.destination.type"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// BackupTarget is the Schema for the backuptargets API. It stores reusable
+// backup destination configuration and credentials so they don't have to be
+// duplicated on every OpenFGAStore, OpenFGABackup or OpenFGARestore.
+type BackupTarget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackupTargetSpec   `json:"spec,omitempty"`
+	Status BackupTargetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BackupTargetList contains a list of BackupTarget
+type BackupTargetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackupTarget `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BackupTarget{}, &BackupTargetList{})
+}
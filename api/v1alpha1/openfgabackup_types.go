@@ -0,0 +1,112 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OpenFGABackupSpec defines the desired state of OpenFGABackup
+type OpenFGABackupSpec struct {
+	// StoreRef selects the OpenFGAStore to back up
+	// +kubebuilder:validation:Required
+	StoreRef StoreReference `json:"storeRef"`
+
+	// DestinationRef points at a reusable BackupTarget describing where the
+	// snapshot is written. Takes precedence over the store's default Backup.DestinationRef
+	DestinationRef *BackupTargetReference `json:"destinationRef,omitempty"`
+
+	// Compression indicates whether the snapshot should be compressed
+	// +kubebuilder:default=true
+	Compression *bool `json:"compression,omitempty"`
+
+	// Encryption configuration applied to this snapshot
+	Encryption *EncryptionConfig `json:"encryption,omitempty"`
+}
+
+// BackupTargetReference references a BackupTarget resource
+type BackupTargetReference struct {
+	// Name is the name of the BackupTarget resource
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the BackupTarget resource. Defaults to the referencing resource's namespace
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// OpenFGABackupStatus defines the observed state of OpenFGABackup
+type OpenFGABackupStatus struct {
+	// Conditions represent the latest available observations of the backup's current state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Phase represents the current phase of the backup
+	// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed;Unknown
+	Phase string `json:"phase,omitempty"`
+
+	// StartTime is when the backup run began
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the backup run finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// BytesWritten is the total number of bytes written to the destination
+	BytesWritten *int64 `json:"bytesWritten,omitempty"`
+
+	// TupleCount is the number of tuples captured in this snapshot
+	TupleCount *int64 `json:"tupleCount,omitempty"`
+
+	// ModelCount is the number of authorization models captured in this snapshot
+	ModelCount *int32 `json:"modelCount,omitempty"`
+
+	// Checksum is the checksum of the snapshot payload, recorded in the snapshot's manifest and used to verify integrity on restore
+	Checksum string `json:"checksum,omitempty"`
+
+	// URL is the resolvable location of the snapshot at its destination
+	URL string `json:"url,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Tuples",type="integer",JSONPath=".status.tupleCount"
+// +kubebuilder:printcolumn:name="Bytes",type="integer",JSONPath=".status.bytesWritten"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// OpenFGABackup is the Schema for the openfgabackups API. Creating one
+// triggers an imperative snapshot of a store outside of its scheduled
+// BackupConfig cadence, and its status records where the snapshot landed so
+// an OpenFGARestore can reference it later.
+type OpenFGABackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OpenFGABackupSpec   `json:"spec,omitempty"`
+	Status OpenFGABackupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OpenFGABackupList contains a list of OpenFGABackup
+type OpenFGABackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpenFGABackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpenFGABackup{}, &OpenFGABackupList{})
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OpenFGAComplianceReportSpec defines the desired state of OpenFGAComplianceReport
+type OpenFGAComplianceReportSpec struct {
+	// ServerRef selects the OpenFGAServer to scan. Mutually exclusive with StoreRef
+	ServerRef *ServerReference `json:"serverRef,omitempty"`
+
+	// StoreRef selects the OpenFGAStore to scan. Mutually exclusive with ServerRef
+	StoreRef *StoreReference `json:"storeRef,omitempty"`
+
+	// Profile selects the rule pack applied during scanning
+	// +kubebuilder:validation:Enum=baseline;restricted;pci
+	// +kubebuilder:default="baseline"
+	Profile string `json:"profile,omitempty"`
+
+	// Schedule holds a cron expression for re-running the scan. The reconciler does not read it and
+	// does not requeue on a timer: the report is scanned each time the object is reconciled
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// ComplianceFinding records the result of evaluating a single compliance rule
+// against a resolved resource
+type ComplianceFinding struct {
+	// RuleID identifies the rule that was evaluated, e.g. "CIS-OPENFGA-1.2"
+	// +kubebuilder:validation:Required
+	RuleID string `json:"ruleID"`
+
+	// Severity of the finding if it failed
+	// +kubebuilder:validation:Enum=low;medium;high;critical
+	Severity string `json:"severity,omitempty"`
+
+	// ResourceRef identifies the resource the rule was evaluated against
+	// +kubebuilder:validation:Required
+	ResourceRef ComplianceResourceReference `json:"resourceRef"`
+
+	// Result is the pass/fail outcome of the rule
+	// +kubebuilder:validation:Enum=pass;fail;skipped
+	Result string `json:"result"`
+
+	// ObservedValue is the value the scanner found on the resource
+	ObservedValue string `json:"observedValue,omitempty"`
+
+	// ExpectedValue is the value the rule requires
+	ExpectedValue string `json:"expectedValue,omitempty"`
+
+	// Remediation provides guidance for bringing the resource into compliance
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// ComplianceResourceReference identifies the Kubernetes resource a compliance
+// finding applies to
+type ComplianceResourceReference struct {
+	// APIVersion of the referenced resource
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind of the referenced resource
+	// +kubebuilder:validation:Required
+	Kind string `json:"kind"`
+
+	// Name of the referenced resource
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the referenced resource
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// OpenFGAComplianceReportStatus defines the observed state of OpenFGAComplianceReport
+type OpenFGAComplianceReportStatus struct {
+	// Conditions represent the latest available observations of the scan's current state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Phase represents the current phase of the compliance scan
+	// +kubebuilder:validation:Enum=Pending;Scanning;Completed;Failed;Unknown
+	Phase string `json:"phase,omitempty"`
+
+	// LastScanTime is the last time a scan completed
+	LastScanTime *metav1.Time `json:"lastScanTime,omitempty"`
+
+	// Findings are the rule-by-rule results from the most recent scan
+	Findings []ComplianceFinding `json:"findings,omitempty"`
+
+	// PassCount is the number of rules that passed in the most recent scan
+	PassCount int32 `json:"passCount,omitempty"`
+
+	// FailCount is the number of rules that failed in the most recent scan
+	FailCount int32 `json:"failCount,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Profile",type="string",JSONPath=".spec.profile"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Pass",type="integer",JSONPath=".status.passCount"
+// +kubebuilder:printcolumn:name="Fail",type="integer",JSONPath=".status.failCount"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// OpenFGAComplianceReport is the Schema for the openfgacompliancereports API.
+// Its controller resolves the referenced OpenFGAServer or OpenFGAStore (and
+// any Secrets/ConfigMaps it references), evaluates them against a
+// profile-selected rule pack, and records rule-by-rule pass/fail findings.
+// Rules that depend on a managed Deployment/Service/NetworkPolicy fail rather
+// than pass, since no controller in this build owns those child resources yet.
+type OpenFGAComplianceReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OpenFGAComplianceReportSpec   `json:"spec,omitempty"`
+	Status OpenFGAComplianceReportStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OpenFGAComplianceReportList contains a list of OpenFGAComplianceReport
+type OpenFGAComplianceReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpenFGAComplianceReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpenFGAComplianceReport{}, &OpenFGAComplianceReportList{})
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OpenFGARestoreSpec defines the desired state of OpenFGARestore
+type OpenFGARestoreSpec struct {
+	// StoreRef selects the OpenFGAStore to restore into
+	// +kubebuilder:validation:Required
+	StoreRef StoreReference `json:"storeRef"`
+
+	// BackupRef points at the OpenFGABackup to restore from. If omitted, the
+	// most recent successful backup for StoreRef is used
+	BackupRef *OpenFGABackupReference `json:"backupRef,omitempty"`
+
+	// DestinationRef overrides where the snapshot is read from. When unset, the
+	// referenced OpenFGABackup's DestinationRef is used, then the store's
+	// Spec.Backup.DestinationRef
+	DestinationRef *BackupTargetReference `json:"destinationRef,omitempty"`
+}
+
+// OpenFGABackupReference references an OpenFGABackup resource. Each
+// OpenFGABackup holds a single point-in-time snapshot, so the reference
+// selects the point in time; there is no finer selection within a backup
+type OpenFGABackupReference struct {
+	// Name is the name of the OpenFGABackup resource
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the OpenFGABackup resource. Defaults to the referencing resource's namespace
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// OpenFGARestoreStatus defines the observed state of OpenFGARestore
+type OpenFGARestoreStatus struct {
+	// Conditions represent the latest available observations of the restore's current state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Phase represents the current phase of the restore
+	// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed;Unknown
+	Phase string `json:"phase,omitempty"`
+
+	// StartTime is when the restore run began
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the restore run finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// TupleCount is the number of tuples written during this restore
+	TupleCount *int64 `json:"tupleCount,omitempty"`
+
+	// ModelCount is the number of authorization models written during this restore
+	ModelCount *int32 `json:"modelCount,omitempty"`
+
+	// Checksum is the payload checksum read from the restored snapshot's manifest at the destination
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Tuples",type="integer",JSONPath=".status.tupleCount"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// OpenFGARestore is the Schema for the openfgarestores API. Creating one
+// rolls a store's tuples and authorization models back to the state
+// captured by a referenced OpenFGABackup.
+type OpenFGARestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OpenFGARestoreSpec   `json:"spec,omitempty"`
+	Status OpenFGARestoreStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OpenFGARestoreList contains a list of OpenFGARestore
+type OpenFGARestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpenFGARestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpenFGARestore{}, &OpenFGARestoreList{})
+}
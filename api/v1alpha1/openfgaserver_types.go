@@ -144,13 +144,28 @@ type NetworkPolicyConfig struct {
 	// +kubebuilder:default=false
 	Enabled *bool `json:"enabled,omitempty"`
 
+	// Engine selects which policy CRD is reconciled. "kubernetes" always
+	// emits a vanilla networking.k8s.io/v1 NetworkPolicy. "cilium" emits a
+	// cilium.io/v2 CiliumNetworkPolicy (or CiliumClusterwideNetworkPolicy
+	// when Scope is "cluster"), gated on the CRD being present in the
+	// cluster, and is required for any rule that sets Cilium L7 matchers
+	// +kubebuilder:validation:Enum=kubernetes;cilium
+	// +kubebuilder:default="kubernetes"
+	Engine string `json:"engine,omitempty"`
+
+	// Scope selects whether the Cilium policy is namespaced or cluster-wide.
+	// Only honored when Engine is "cilium"
+	// +kubebuilder:validation:Enum=namespace;cluster
+	// +kubebuilder:default="namespace"
+	Scope string `json:"scope,omitempty"`
+
 	// AllowedIngress defines allowed ingress rules
 	AllowedIngress []NetworkPolicyRule `json:"allowedIngress,omitempty"`
 
 	// AllowedEgress defines allowed egress rules
 	AllowedEgress []NetworkPolicyRule `json:"allowedEgress,omitempty"`
 
-	// CiliumLabels are labels for Cilium-specific policies
+	// CiliumLabels are labels applied to the generated CiliumNetworkPolicy/CiliumClusterwideNetworkPolicy
 	CiliumLabels map[string]string `json:"ciliumLabels,omitempty"`
 }
 
@@ -164,6 +179,42 @@ type NetworkPolicyRule struct {
 
 	// Ports defines the allowed ports
 	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+
+	// Cilium carries L7 HTTP/gRPC matchers for this rule. It is only
+	// honored when NetworkPolicyConfig.Engine is "cilium"; the vanilla
+	// networking.k8s.io/v1 NetworkPolicy output ignores it
+	Cilium *CiliumL7Rule `json:"cilium,omitempty"`
+}
+
+// CiliumL7Rule defines Cilium-specific L7 matchers for a network policy rule
+type CiliumL7Rule struct {
+	// HTTP restricts the rule to matching HTTP requests
+	HTTP []CiliumHTTPRule `json:"http,omitempty"`
+
+	// GRPC restricts the rule to matching gRPC calls
+	GRPC []CiliumGRPCRule `json:"grpc,omitempty"`
+}
+
+// CiliumHTTPRule matches an HTTP request by method, path and headers
+type CiliumHTTPRule struct {
+	// Method is the HTTP method to match, e.g. "POST". Empty matches any method
+	Method string `json:"method,omitempty"`
+
+	// Path is a regular expression matched against the request path, e.g. "^/stores/[^/]+/check$"
+	Path string `json:"path,omitempty"`
+
+	// Headers are header match expressions in the form "name: value"
+	Headers []string `json:"headers,omitempty"`
+}
+
+// CiliumGRPCRule matches a gRPC call by fully-qualified service and method
+type CiliumGRPCRule struct {
+	// Service is the fully-qualified gRPC service name, e.g. "openfga.v1.OpenFGAService"
+	// +kubebuilder:validation:Required
+	Service string `json:"service"`
+
+	// Method is the gRPC method name, e.g. "Write". Empty matches any method on Service
+	Method string `json:"method,omitempty"`
 }
 
 // NetworkPolicyPeer defines a network policy peer
@@ -0,0 +1,195 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OpenFGAServerConnectionSpec defines the desired state of OpenFGAServerConnection.
+// It models an external OpenFGA endpoint using a kubeconfig-like structure of
+// named clusters, users and contexts, so a single operator instance can
+// manage stores across a fleet of OpenFGA servers that are not necessarily
+// in-cluster OpenFGAServer resources.
+type OpenFGAServerConnectionSpec struct {
+	// Clusters are named OpenFGA server endpoints
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Clusters []OpenFGAConnectionCluster `json:"clusters"`
+
+	// Users are named credential sets usable against any cluster
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Users []OpenFGAConnectionUser `json:"users"`
+
+	// Contexts bind a cluster to a user
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Contexts []OpenFGAConnectionContext `json:"contexts"`
+
+	// CurrentContext is the name of the context used to build the OpenFGA API client
+	// +kubebuilder:validation:Required
+	CurrentContext string `json:"currentContext"`
+}
+
+// OpenFGAConnectionCluster defines a named external OpenFGA endpoint
+type OpenFGAConnectionCluster struct {
+	// Name identifies this cluster within the connection
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Server is the OpenFGA HTTP or gRPC API base URL
+	// +kubebuilder:validation:Required
+	Server string `json:"server"`
+
+	// CABundleSecret references the secret key containing the PEM-encoded CA bundle to verify Server
+	CABundleSecret *corev1.SecretKeySelector `json:"caBundleSecret,omitempty"`
+
+	// InsecureSkipTLSVerify disables TLS certificate verification. Not recommended outside of development
+	// +kubebuilder:default=false
+	InsecureSkipTLSVerify *bool `json:"insecureSkipTLSVerify,omitempty"`
+}
+
+// OpenFGAConnectionUser defines a named credential set for authenticating to an OpenFGA server
+type OpenFGAConnectionUser struct {
+	// Name identifies this user within the connection
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// BearerTokenSecret references the secret key containing a static bearer token
+	BearerTokenSecret *corev1.SecretKeySelector `json:"bearerTokenSecret,omitempty"`
+
+	// ClientCertSecret references the secret containing a client certificate and key for mTLS
+	ClientCertSecret *corev1.SecretReference `json:"clientCertSecret,omitempty"`
+
+	// OIDC configures OAuth2/OIDC client-credentials authentication
+	OIDC *OIDCAuth `json:"oidc,omitempty"`
+
+	// AWSSigV4 configures AWS SigV4 request signing, for OpenFGA deployments fronted by an AWS-compatible gateway
+	AWSSigV4 *AWSSigV4Auth `json:"awsSigV4,omitempty"`
+}
+
+// OIDCAuth configures OIDC client-credentials authentication with automatic token refresh
+type OIDCAuth struct {
+	// IssuerURL is the OIDC issuer's base URL
+	// +kubebuilder:validation:Required
+	IssuerURL string `json:"issuerURL"`
+
+	// ClientID is the OAuth2 client ID
+	// +kubebuilder:validation:Required
+	ClientID string `json:"clientID"`
+
+	// ClientSecretSecret references the secret key containing the OAuth2 client secret
+	// +kubebuilder:validation:Required
+	ClientSecretSecret *corev1.SecretKeySelector `json:"clientSecretSecret"`
+
+	// Scopes requested for the client-credentials token
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// AWSSigV4Auth configures AWS Signature Version 4 request signing
+type AWSSigV4Auth struct {
+	// Region is the AWS region used to sign requests
+	// +kubebuilder:validation:Required
+	Region string `json:"region"`
+
+	// Service is the AWS service name used to sign requests, e.g. "execute-api"
+	// +kubebuilder:default="execute-api"
+	Service string `json:"service,omitempty"`
+
+	// CredentialsSecret references the secret containing the AWS access key ID and secret access key.
+	// If omitted, the operator's own IAM role/IRSA identity is used
+	CredentialsSecret *corev1.SecretReference `json:"credentialsSecret,omitempty"`
+}
+
+// OpenFGAConnectionContext binds a named cluster to a named user
+type OpenFGAConnectionContext struct {
+	// Name identifies this context within the connection
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Cluster is the name of the OpenFGAConnectionCluster to use
+	// +kubebuilder:validation:Required
+	Cluster string `json:"cluster"`
+
+	// User is the name of the OpenFGAConnectionUser to use
+	// +kubebuilder:validation:Required
+	User string `json:"user"`
+}
+
+// OpenFGAServerConnectionReference references an OpenFGAServerConnection resource
+type OpenFGAServerConnectionReference struct {
+	// Name is the name of the OpenFGAServerConnection resource
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the OpenFGAServerConnection resource. Defaults to the referencing resource's namespace
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// OpenFGAServerConnectionStatus defines the observed state of OpenFGAServerConnection
+type OpenFGAServerConnectionStatus struct {
+	// Conditions represent the latest available observations of the connection's current state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Phase represents the current phase of the connection
+	// +kubebuilder:validation:Enum=Pending;Ready;Failed;Unknown
+	Phase string `json:"phase,omitempty"`
+
+	// ResolvedServer is the server URL currently resolved from CurrentContext
+	ResolvedServer string `json:"resolvedServer,omitempty"`
+
+	// LastTokenRefresh is the last time an OIDC token was refreshed for this connection
+	LastTokenRefresh *metav1.Time `json:"lastTokenRefresh,omitempty"`
+
+	// LastReconcileTime is the last time the resource was reconciled
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Context",type="string",JSONPath=".spec.currentContext"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Server",type="string",JSONPath=".status.resolvedServer"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// OpenFGAServerConnection is the Schema for the openfgaserverconnections API.
+// OpenFGAStoreSpec.ServerRef keeps pointing at an in-cluster OpenFGAServer by
+// default; a store may instead set ConnectionRef to resolve its OpenFGA API
+// client through an OpenFGAServerConnection, allowing stores in other
+// clusters, in SaaS, or run outside Kubernetes entirely.
+type OpenFGAServerConnection struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OpenFGAServerConnectionSpec   `json:"spec,omitempty"`
+	Status OpenFGAServerConnectionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OpenFGAServerConnectionList contains a list of OpenFGAServerConnection
+type OpenFGAServerConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpenFGAServerConnection `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpenFGAServerConnection{}, &OpenFGAServerConnectionList{})
+}
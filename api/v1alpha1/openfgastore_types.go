@@ -23,10 +23,17 @@ import (
 
 // OpenFGAStoreSpec defines the desired state of OpenFGAStore
 type OpenFGAStoreSpec struct {
-	// ServerRef is a reference to the OpenFGAServer where this store will be created
+	// ServerRef is a reference to the in-cluster OpenFGAServer where this
+	// store will be created. Ignored when ConnectionRef is set
 	// +kubebuilder:validation:Required
 	ServerRef ServerReference `json:"serverRef"`
 
+	// ConnectionRef points at an OpenFGAServerConnection describing an
+	// external OpenFGA endpoint (another cluster, a SaaS deployment, or a
+	// standalone process) to manage this store against instead of an
+	// in-cluster OpenFGAServer. When set, it takes precedence over ServerRef
+	ConnectionRef *OpenFGAServerConnectionReference `json:"connectionRef,omitempty"`
+
 	// DisplayName is a human-readable name for the store
 	DisplayName string `json:"displayName,omitempty"`
 
@@ -143,7 +150,9 @@ type BackupConfig struct {
 	// +kubebuilder:default=false
 	Enabled *bool `json:"enabled,omitempty"`
 
-	// Schedule defines the backup schedule in cron format
+	// Schedule defines the backup schedule in cron format. Not wired into a
+	// CronJob or any other requeueing yet - creating an OpenFGABackup is
+	// currently the only way to trigger a run
 	// +kubebuilder:validation:Pattern="^(@(annually|yearly|monthly|weekly|daily|hourly|reboot))|(@every (\\d+(ns|us|Âµs|ms|s|m|h))+)|((((\\d+,)+\\d+|(\\d+([/\\-]\\d+)?)|\\*) ){4,6}(((\\d+,)+\\d+|(\\d+([/\\-]\\d+)?)|\\*)( |$)))$"
 	Schedule string `json:"schedule,omitempty"`
 
@@ -164,6 +173,11 @@ type BackupConfig struct {
 
 	// Encryption configuration for backups
 	Encryption *EncryptionConfig `json:"encryption,omitempty"`
+
+	// DestinationRef points at a reusable BackupTarget so destination
+	// configuration and credentials aren't duplicated per store. Takes
+	// precedence over StorageClass/StorageSize when set
+	DestinationRef *BackupTargetReference `json:"destinationRef,omitempty"`
 }
 
 // EncryptionConfig defines encryption configuration
@@ -172,12 +186,127 @@ type EncryptionConfig struct {
 	// +kubebuilder:default=false
 	Enabled *bool `json:"enabled,omitempty"`
 
-	// Algorithm is the encryption algorithm to use
+	// Algorithm is the encryption algorithm used for the data encryption key
 	// +kubebuilder:validation:Enum=AES256;AES128;ChaCha20Poly1305
 	Algorithm string `json:"algorithm,omitempty"`
 
-	// KeySecret contains the reference to the secret containing the encryption key
+	// Provider selects how the data encryption key (DEK) is wrapped. With
+	// "secret", a fresh DEK is generated per backup, wrapped with the 32-byte
+	// AES key read from KeySecret, and persisted alongside the ciphertext.
+	// The other values are accepted by the API but not implemented by the
+	// reconcilers: a backup, restore or key-rotation check that selects one
+	// fails with an error
+	// +kubebuilder:validation:Enum=secret;aws-kms;gcp-kms;azure-keyvault;vault-transit;pkcs11
+	// +kubebuilder:default="secret"
+	Provider string `json:"provider,omitempty"`
+
+	// KeySecret contains the reference to the secret containing the encryption
+	// key. Required when Provider is "secret"
 	KeySecret *corev1.SecretKeySelector `json:"keySecret,omitempty"`
+
+	// AWSKMS configures DEK wrapping via AWS KMS. Required when Provider is "aws-kms"
+	AWSKMS *AWSKMSProvider `json:"awsKMS,omitempty"`
+
+	// GCPKMS configures DEK wrapping via Google Cloud KMS. Required when Provider is "gcp-kms"
+	GCPKMS *GCPKMSProvider `json:"gcpKMS,omitempty"`
+
+	// AzureKeyVault configures DEK wrapping via Azure Key Vault. Required when Provider is "azure-keyvault"
+	AzureKeyVault *AzureKeyVaultProvider `json:"azureKeyVault,omitempty"`
+
+	// VaultTransit configures DEK wrapping via HashiCorp Vault's transit secrets engine. Required when Provider is "vault-transit"
+	VaultTransit *VaultTransitProvider `json:"vaultTransit,omitempty"`
+
+	// PKCS11 configures DEK wrapping via a PKCS#11 HSM. Required when Provider is "pkcs11"
+	PKCS11 *PKCS11Provider `json:"pkcs11,omitempty"`
+
+	// RotationPolicy controls how often the active key material is rotated
+	RotationPolicy *RotationPolicy `json:"rotationPolicy,omitempty"`
+}
+
+// AWSKMSProvider configures envelope encryption via AWS KMS
+type AWSKMSProvider struct {
+	// KeyARN is the ARN of the KMS key used to wrap data encryption keys
+	// +kubebuilder:validation:Required
+	KeyARN string `json:"keyARN"`
+
+	// Region is the AWS region of the KMS key
+	Region string `json:"region,omitempty"`
+
+	// RoleARN is an IAM role to assume before calling KMS, e.g. for IRSA
+	RoleARN string `json:"roleARN,omitempty"`
+}
+
+// GCPKMSProvider configures envelope encryption via Google Cloud KMS
+type GCPKMSProvider struct {
+	// KeyURI is the full Cloud KMS key resource name, e.g.
+	// "projects/p/locations/l/keyRings/r/cryptoKeys/k"
+	// +kubebuilder:validation:Required
+	KeyURI string `json:"keyURI"`
+
+	// WorkloadIdentityServiceAccount is the GCP service account to impersonate via Workload Identity
+	WorkloadIdentityServiceAccount string `json:"workloadIdentityServiceAccount,omitempty"`
+}
+
+// AzureKeyVaultProvider configures envelope encryption via Azure Key Vault
+type AzureKeyVaultProvider struct {
+	// VaultURL is the base URL of the Azure Key Vault, e.g. "https://my-vault.vault.azure.net"
+	// +kubebuilder:validation:Required
+	VaultURL string `json:"vaultURL"`
+
+	// KeyName is the name of the key within the vault
+	// +kubebuilder:validation:Required
+	KeyName string `json:"keyName"`
+
+	// KeyVersion pins a specific key version. Defaults to the latest version
+	KeyVersion string `json:"keyVersion,omitempty"`
+}
+
+// VaultTransitProvider configures envelope encryption via HashiCorp Vault's transit engine
+type VaultTransitProvider struct {
+	// Address is the Vault server address
+	// +kubebuilder:validation:Required
+	Address string `json:"address"`
+
+	// MountPath is the path the transit secrets engine is mounted at
+	// +kubebuilder:default="transit"
+	MountPath string `json:"mountPath,omitempty"`
+
+	// KeyName is the name of the transit key used to wrap data encryption keys
+	// +kubebuilder:validation:Required
+	KeyName string `json:"keyName"`
+
+	// Role is the Vault auth role used to obtain a token
+	Role string `json:"role,omitempty"`
+}
+
+// PKCS11Provider configures envelope encryption via a PKCS#11 HSM
+type PKCS11Provider struct {
+	// ModulePath is the filesystem path to the PKCS#11 module, mounted into the operator pod
+	// +kubebuilder:validation:Required
+	ModulePath string `json:"modulePath"`
+
+	// SlotID identifies the HSM slot to use
+	// +kubebuilder:validation:Required
+	SlotID int64 `json:"slotID"`
+
+	// KeyLabel is the label of the wrapping key within the slot
+	// +kubebuilder:validation:Required
+	KeyLabel string `json:"keyLabel"`
+
+	// PINSecret contains the reference to the secret holding the slot PIN
+	PINSecret *corev1.SecretKeySelector `json:"pinSecret,omitempty"`
+}
+
+// RotationPolicy defines key-rotation semantics for an EncryptionConfig
+type RotationPolicy struct {
+	// MaxKeyAge is the maximum age of an active data encryption key before it is rotated
+	MaxKeyAge *metav1.Duration `json:"maxKeyAge,omitempty"`
+
+	// RotateOnSchedule holds a cron schedule for re-wrapping the active DEK
+	// under the provider's current key. The store reconciler does not read
+	// it: a new key version is recorded only when MaxKeyAge has elapsed (or
+	// none is recorded yet), and existing backups are not re-wrapped
+	RotateOnSchedule string `json:"rotateOnSchedule,omitempty"`
 }
 
 // MetricsConfig defines metrics configuration for a store
@@ -266,6 +395,13 @@ type OpenFGAStoreStatus struct {
 
 	// MetricsEndpoint is the endpoint where metrics are available
 	MetricsEndpoint string `json:"metricsEndpoint,omitempty"`
+
+	// LastKeyRotation is the last time the backup encryption key was rotated
+	LastKeyRotation *metav1.Time `json:"lastKeyRotation,omitempty"`
+
+	// ActiveKeyVersion identifies the currently active wrapping key version
+	// reported by the configured KMS provider
+	ActiveKeyVersion string `json:"activeKeyVersion,omitempty"`
 }
 
 // +kubebuilder:object:root=true
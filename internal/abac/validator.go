@@ -0,0 +1,116 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package abac type-checks the CEL expressions in an AuthorizationSchema's
+// named Conditions, the way OpenFGA itself would at model-write time, so a
+// broken condition is rejected before the model is ever pushed to a store.
+package abac
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+// ValidateConditions compiles every condition's Expression against an
+// environment built from its declared Parameters and returns one error per
+// condition that fails to parse, fails to type-check, or does not evaluate
+// to a boolean. It does not evaluate expressions against any real context -
+// only that they would compile for any input matching Parameters
+func ValidateConditions(conditions map[string]v1alpha1.Condition) []error {
+	var errs []error
+	for name, cond := range conditions {
+		if err := validateCondition(name, cond); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func validateCondition(name string, cond v1alpha1.Condition) error {
+	opts := make([]cel.EnvOption, 0, len(cond.Parameters))
+	for paramName, paramType := range cond.Parameters {
+		t, err := celType(paramType)
+		if err != nil {
+			return fmt.Errorf("condition %q parameter %q: %w", name, paramName, err)
+		}
+		opts = append(opts, cel.Variable(paramName, t))
+	}
+
+	env, err := cel.NewEnv(opts...)
+	if err != nil {
+		return fmt.Errorf("condition %q: building CEL environment: %w", name, err)
+	}
+
+	ast, issues := env.Compile(cond.Expression)
+	if issues != nil && issues.Err() != nil {
+		return fmt.Errorf("condition %q: %w", name, issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return fmt.Errorf("condition %q: expression must evaluate to a bool, got %s", name, ast.OutputType())
+	}
+	return nil
+}
+
+// celType maps an OpenFGA condition parameter type to the cel-go type used
+// to type-check its owning condition's Expression
+func celType(t v1alpha1.ConditionParamType) (*cel.Type, error) {
+	switch t.TypeName {
+	case "string":
+		return cel.StringType, nil
+	case "bool":
+		return cel.BoolType, nil
+	case "int":
+		return cel.IntType, nil
+	case "uint":
+		return cel.UintType, nil
+	case "double":
+		return cel.DoubleType, nil
+	case "duration":
+		return cel.DurationType, nil
+	case "timestamp":
+		return cel.TimestampType, nil
+	case "ipaddress":
+		// OpenFGA's ipaddress type has no native CEL equivalent; it is
+		// passed to conditions as a string and compared via extension
+		// functions, so it type-checks here as a plain string.
+		return cel.StringType, nil
+	case "any":
+		return cel.DynType, nil
+	case "list":
+		if len(t.GenericTypes) != 1 {
+			return nil, fmt.Errorf("list type requires exactly one element type, got %d", len(t.GenericTypes))
+		}
+		elem, err := celType(t.GenericTypes[0])
+		if err != nil {
+			return nil, fmt.Errorf("list element type: %w", err)
+		}
+		return cel.ListType(elem), nil
+	case "map":
+		if len(t.GenericTypes) != 1 {
+			return nil, fmt.Errorf("map type requires exactly one value type, got %d", len(t.GenericTypes))
+		}
+		value, err := celType(t.GenericTypes[0])
+		if err != nil {
+			return nil, fmt.Errorf("map value type: %w", err)
+		}
+		return cel.MapType(cel.StringType, value), nil
+	default:
+		return nil, fmt.Errorf("unsupported typeName %q", t.TypeName)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package abac
+
+import (
+	"testing"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+func TestValidateConditionsAcceptsWellTypedExpression(t *testing.T) {
+	conditions := map[string]v1alpha1.Condition{
+		"non_expired_grant": {
+			Expression: "request_time < grant_expiry",
+			Parameters: map[string]v1alpha1.ConditionParamType{
+				"request_time": {TypeName: "timestamp"},
+				"grant_expiry": {TypeName: "timestamp"},
+			},
+		},
+	}
+
+	if errs := ValidateConditions(conditions); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidateConditionsRejectsSyntaxError(t *testing.T) {
+	conditions := map[string]v1alpha1.Condition{
+		"broken": {
+			Expression: "request_time <",
+			Parameters: map[string]v1alpha1.ConditionParamType{
+				"request_time": {TypeName: "timestamp"},
+			},
+		},
+	}
+
+	errs := ValidateConditions(conditions)
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly one error, got %v", errs)
+	}
+}
+
+func TestValidateConditionsRejectsNonBoolResult(t *testing.T) {
+	conditions := map[string]v1alpha1.Condition{
+		"not_a_predicate": {
+			Expression: "request_time",
+			Parameters: map[string]v1alpha1.ConditionParamType{
+				"request_time": {TypeName: "timestamp"},
+			},
+		},
+	}
+
+	errs := ValidateConditions(conditions)
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly one error, got %v", errs)
+	}
+}
+
+func TestValidateConditionsRejectsUndeclaredVariable(t *testing.T) {
+	conditions := map[string]v1alpha1.Condition{
+		"undeclared": {
+			Expression: "unknown_param < 5",
+			Parameters: map[string]v1alpha1.ConditionParamType{},
+		},
+	}
+
+	errs := ValidateConditions(conditions)
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly one error, got %v", errs)
+	}
+}
+
+func TestValidateConditionsSupportsListAndMapParameters(t *testing.T) {
+	conditions := map[string]v1alpha1.Condition{
+		"allowed_ips": {
+			Expression: `"10.0.0.1" in allowed && labels["env"] == "prod"`,
+			Parameters: map[string]v1alpha1.ConditionParamType{
+				"allowed": {
+					TypeName:     "list",
+					GenericTypes: []v1alpha1.ConditionParamType{{TypeName: "ipaddress"}},
+				},
+				"labels": {
+					TypeName:     "map",
+					GenericTypes: []v1alpha1.ConditionParamType{{TypeName: "string"}},
+				},
+			},
+		},
+	}
+
+	if errs := ValidateConditions(conditions); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidateConditionsRejectsUnsupportedParamType(t *testing.T) {
+	conditions := map[string]v1alpha1.Condition{
+		"bad_param": {
+			Expression: "x == 1",
+			Parameters: map[string]v1alpha1.ConditionParamType{
+				"x": {TypeName: "not_a_real_type"},
+			},
+		},
+	}
+
+	errs := ValidateConditions(conditions)
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly one error, got %v", errs)
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backupengine implements the data path behind OpenFGABackup and
+// OpenFGARestore: streaming a store's tuples and authorization models via
+// openfga.Client, optionally compressing and encrypting the result, and
+// reversing the process on restore. It has no knowledge of Kubernetes or of
+// where the resulting bytes are ultimately written - that is
+// controller.DestinationWriter's job.
+package backupengine
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jralmaraz/authcore-openfga-operator/internal/kms"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/openfga"
+)
+
+// Manifest is the small JSON document persisted alongside a snapshot's
+// payload bytes, recording enough to verify and reverse the transformations
+// applied to it.
+type Manifest struct {
+	TupleCount int64           `json:"tupleCount"`
+	ModelCount int32           `json:"modelCount"`
+	Compressed bool            `json:"compressed"`
+	Wrapped    *kms.WrappedKey `json:"wrapped,omitempty"`
+	Checksum   string          `json:"checksum"`
+}
+
+// Snapshot is the output of Run: the manifest and the resulting payload
+// bytes (gzip-compressed and/or AES-256-GCM-encrypted per Manifest)
+type Snapshot struct {
+	Manifest Manifest
+	Payload  []byte
+}
+
+// UnwrapError indicates a snapshot's data encryption key failed to unwrap via
+// its recorded kms.Provider, signalling a KMS outage or misconfiguration
+// rather than a corrupt or tampered snapshot. Callers can use errors.As to
+// distinguish this from other Restore failures, e.g. to emit
+// kms.EventReasonUnwrapFailed.
+type UnwrapError struct {
+	Err error
+}
+
+func (e *UnwrapError) Error() string { return fmt.Sprintf("unwrapping data encryption key: %s", e.Err) }
+func (e *UnwrapError) Unwrap() error { return e.Err }
+
+type payloadDocument struct {
+	Tuples []openfga.Tuple `json:"tuples"`
+	Models []openfga.Model `json:"models"`
+}
+
+// Run streams every tuple and authorization model out of storeID, encodes
+// them as JSON, optionally gzip-compresses the result, and - when provider is
+// non-nil - generates a fresh data encryption key and seals the payload with
+// it, persisting the provider-wrapped DEK in Manifest.Wrapped.
+func Run(ctx context.Context, cli openfga.Client, storeID string, compress bool, provider kms.Provider) (Snapshot, error) {
+	var doc payloadDocument
+
+	if err := cli.StreamTuples(ctx, storeID, 1000, func(page []openfga.Tuple) error {
+		doc.Tuples = append(doc.Tuples, page...)
+		return nil
+	}); err != nil {
+		return Snapshot{}, fmt.Errorf("streaming tuples: %w", err)
+	}
+
+	if err := cli.StreamAuthorizationModels(ctx, storeID, func(m openfga.Model) error {
+		doc.Models = append(doc.Models, m)
+		return nil
+	}); err != nil {
+		return Snapshot{}, fmt.Errorf("streaming authorization models: %w", err)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	manifest := Manifest{
+		TupleCount: int64(len(doc.Tuples)),
+		ModelCount: int32(len(doc.Models)),
+	}
+
+	if compress {
+		data, err = gzipCompress(data)
+		if err != nil {
+			return Snapshot{}, err
+		}
+		manifest.Compressed = true
+	}
+
+	if provider != nil {
+		dek, wrapped, err := provider.GenerateDEK(ctx)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("generating data encryption key: %w", err)
+		}
+		data, err = seal(dek, data)
+		if err != nil {
+			return Snapshot{}, err
+		}
+		manifest.Wrapped = &wrapped
+	}
+
+	sum := sha256.Sum256(data)
+	manifest.Checksum = hex.EncodeToString(sum[:])
+
+	return Snapshot{Manifest: manifest, Payload: data}, nil
+}
+
+// Restore reverses Run: verifies the checksum, unwraps and decrypts the
+// payload (when Manifest.Wrapped is set), decompresses it (when
+// Manifest.Compressed), and replays every authorization model and tuple it
+// contains into storeID via cli. Models are replayed before tuples so any
+// tuple referencing a condition from a replayed model can be written
+// immediately after.
+func Restore(ctx context.Context, cli openfga.Client, storeID string, snapshot Snapshot, provider kms.Provider) error {
+	sum := sha256.Sum256(snapshot.Payload)
+	if hex.EncodeToString(sum[:]) != snapshot.Manifest.Checksum {
+		return fmt.Errorf("checksum mismatch: snapshot payload may be corrupt or tampered with")
+	}
+
+	data := snapshot.Payload
+
+	if snapshot.Manifest.Wrapped != nil {
+		if provider == nil {
+			return fmt.Errorf("snapshot is encrypted but no KMS provider was configured for restore")
+		}
+		dek, err := provider.Unwrap(ctx, *snapshot.Manifest.Wrapped)
+		if err != nil {
+			return &UnwrapError{Err: err}
+		}
+		data, err = open(dek, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if snapshot.Manifest.Compressed {
+		var err error
+		data, err = gzipDecompress(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	var doc payloadDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("decoding snapshot: %w", err)
+	}
+
+	for _, m := range doc.Models {
+		if _, err := cli.WriteAuthorizationModel(ctx, storeID, m.Document); err != nil {
+			return fmt.Errorf("replaying authorization model %s: %w", m.ID, err)
+		}
+	}
+	if len(doc.Tuples) > 0 {
+		if err := cli.WriteTuples(ctx, storeID, doc.Tuples, nil); err != nil {
+			return fmt.Errorf("replaying tuples: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, fmt.Errorf("compressing snapshot: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("compressing snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func gzipDecompress(data []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing snapshot: %w", err)
+	}
+	defer gz.Close()
+	out, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing snapshot: %w", err)
+	}
+	return out, nil
+}
+
+// seal/open encrypt the snapshot payload itself with the per-backup DEK,
+// separately from how the DEK was wrapped by the configured kms.Provider
+func seal(dek, plaintext []byte) ([]byte, error) {
+	gcm, err := gcmFor(dek)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(dek, ciphertext []byte) ([]byte, error) {
+	gcm, err := gcmFor(dek)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext shorter than nonce size")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting snapshot: %w", err)
+	}
+	return plaintext, nil
+}
+
+func gcmFor(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("building AES cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
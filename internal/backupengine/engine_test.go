@@ -0,0 +1,189 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupengine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jralmaraz/authcore-openfga-operator/internal/kms"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/openfga"
+)
+
+// fakeClient is a minimal openfga.Client backed by in-memory slices, just
+// enough to exercise Run/Restore without a live server.
+type fakeClient struct {
+	tuples []openfga.Tuple
+	models []openfga.Model
+
+	written       []openfga.Tuple
+	writtenModels []openfga.Model
+}
+
+func (f *fakeClient) StreamTuples(ctx context.Context, storeID string, pageSize int32, fn func([]openfga.Tuple) error) error {
+	return fn(f.tuples)
+}
+
+func (f *fakeClient) StreamAuthorizationModels(ctx context.Context, storeID string, fn func(openfga.Model) error) error {
+	for _, m := range f.models {
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeClient) WriteTuples(ctx context.Context, storeID string, writes, deletes []openfga.Tuple) error {
+	f.written = append(f.written, writes...)
+	return nil
+}
+
+func (f *fakeClient) WriteAuthorizationModel(ctx context.Context, storeID string, document []byte) (string, error) {
+	f.writtenModels = append(f.writtenModels, openfga.Model{ID: "replayed", Document: document})
+	return "replayed", nil
+}
+
+func (f *fakeClient) Check(ctx context.Context, storeID, modelID, user, relation, object string, context map[string]interface{}) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeClient) Expand(ctx context.Context, storeID, modelID, object, relation string) (openfga.UsersetTreeNode, error) {
+	return openfga.UsersetTreeNode{}, nil
+}
+
+func (f *fakeClient) ListObjects(ctx context.Context, storeID, modelID, user, relation, objectType string) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakeClient) ListUsers(ctx context.Context, storeID, modelID, object, relation string, userFilters []string) ([]string, error) {
+	return nil, nil
+}
+
+func testClient() *fakeClient {
+	return &fakeClient{
+		tuples: []openfga.Tuple{
+			{User: "user:anne", Relation: "viewer", Object: "document:1"},
+			{User: "user:bob", Relation: "editor", Object: "document:2"},
+		},
+		models: []openfga.Model{
+			{ID: "model-1", Document: []byte(`{"type_definitions":[]}`)},
+		},
+	}
+}
+
+func TestRunAndRestoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := testClient()
+
+	snapshot, err := Run(ctx, source, "store-1", false, nil)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if snapshot.Manifest.TupleCount != 2 {
+		t.Fatalf("expected tupleCount 2, got %d", snapshot.Manifest.TupleCount)
+	}
+	if snapshot.Manifest.ModelCount != 1 {
+		t.Fatalf("expected modelCount 1, got %d", snapshot.Manifest.ModelCount)
+	}
+	if snapshot.Manifest.Compressed {
+		t.Fatalf("expected Compressed false")
+	}
+
+	target := &fakeClient{}
+	if err := Restore(ctx, target, "store-2", snapshot, nil); err != nil {
+		t.Fatalf("Restore returned error: %v", err)
+	}
+	if len(target.written) != 2 {
+		t.Fatalf("expected 2 tuples replayed, got %d", len(target.written))
+	}
+	if len(target.writtenModels) != 1 {
+		t.Fatalf("expected 1 model replayed, got %d", len(target.writtenModels))
+	}
+}
+
+func TestRunCompressesWhenRequested(t *testing.T) {
+	ctx := context.Background()
+	snapshot, err := Run(ctx, testClient(), "store-1", true, nil)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !snapshot.Manifest.Compressed {
+		t.Fatalf("expected Compressed true")
+	}
+
+	target := &fakeClient{}
+	if err := Restore(ctx, target, "store-2", snapshot, nil); err != nil {
+		t.Fatalf("Restore returned error: %v", err)
+	}
+	if len(target.written) != 2 {
+		t.Fatalf("expected 2 tuples replayed after decompression, got %d", len(target.written))
+	}
+}
+
+func TestRunEncryptsAndRestoreDecryptsWithProvider(t *testing.T) {
+	ctx := context.Background()
+	provider, err := kms.NewSecretProvider(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("NewSecretProvider returned error: %v", err)
+	}
+
+	snapshot, err := Run(ctx, testClient(), "store-1", false, provider)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if snapshot.Manifest.Wrapped == nil {
+		t.Fatalf("expected Wrapped to be set when a provider is configured")
+	}
+
+	target := &fakeClient{}
+	if err := Restore(ctx, target, "store-2", snapshot, provider); err != nil {
+		t.Fatalf("Restore returned error: %v", err)
+	}
+	if len(target.written) != 2 {
+		t.Fatalf("expected 2 tuples replayed after decryption, got %d", len(target.written))
+	}
+}
+
+func TestRestoreRejectsEncryptedSnapshotWithoutProvider(t *testing.T) {
+	ctx := context.Background()
+	provider, err := kms.NewSecretProvider(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("NewSecretProvider returned error: %v", err)
+	}
+
+	snapshot, err := Run(ctx, testClient(), "store-1", false, provider)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if err := Restore(ctx, &fakeClient{}, "store-2", snapshot, nil); err == nil {
+		t.Fatalf("expected an error restoring an encrypted snapshot without a provider")
+	}
+}
+
+func TestRestoreRejectsCorruptPayload(t *testing.T) {
+	ctx := context.Background()
+	snapshot, err := Run(ctx, testClient(), "store-1", false, nil)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	snapshot.Payload = append(snapshot.Payload, 0xFF)
+
+	if err := Restore(ctx, &fakeClient{}, "store-2", snapshot, nil); err == nil {
+		t.Fatalf("expected a checksum mismatch error")
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compliance
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var ruleResultTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "openfga_compliance_rule_result_total",
+		Help: "Count of compliance rule evaluations by rule ID, profile and result (pass/fail)",
+	},
+	[]string{"rule_id", "profile", "result"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(ruleResultTotal)
+}
+
+// RecordResult increments the pass/fail counter for a single rule evaluation
+// so operators can alert on compliance drift over time.
+func RecordResult(ruleID, profile string, passed bool) {
+	result := "fail"
+	if passed {
+		result = "pass"
+	}
+	ruleResultTotal.WithLabelValues(ruleID, profile, result).Inc()
+}
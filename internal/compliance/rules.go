@@ -0,0 +1,296 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package compliance implements the rule packs and scanning logic behind the
+// OpenFGAComplianceReport CRD. It walks managed OpenFGAServer/OpenFGAStore
+// resources and the Deployments/Services/NetworkPolicies they own, evaluating
+// each against a profile-selected set of CIS-style rules.
+package compliance
+
+import (
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+// Profile names accepted by OpenFGAComplianceReportSpec.Profile
+const (
+	ProfileBaseline   = "baseline"
+	ProfileRestricted = "restricted"
+	ProfilePCI        = "pci"
+)
+
+// Rule describes a single compliance check. Check receives the fully
+// resolved object graph for one OpenFGAServer or OpenFGAStore (the resource
+// itself plus its generated Deployments, Services and NetworkPolicies, with
+// any referenced Secrets/ConfigMaps already dereferenced into Target) and
+// returns the finding that should be recorded for it.
+type Rule struct {
+	// ID is the stable rule identifier, e.g. "CIS-OPENFGA-1.2"
+	ID string
+
+	// Severity is the severity to report when the rule fails
+	Severity string
+
+	// Description is a short human-readable summary of what the rule checks
+	Description string
+
+	// Remediation is surfaced on a failing finding to guide operators
+	Remediation string
+
+	// Check evaluates the rule against a resolved target and returns the
+	// observed value, expected value and whether the rule passed
+	Check func(target *Target) (observed string, expected string, passed bool)
+}
+
+// Target is the resolved object graph a Rule is evaluated against.
+type Target struct {
+	Server *v1alpha1.OpenFGAServer
+	Store  *v1alpha1.OpenFGAStore
+
+	// Deployment, Service and NetworkPolicy are the generated children of
+	// Server/Store, decoded as unstructured maps so rules can inspect
+	// fields (e.g. container security context) without importing the
+	// concrete apps/v1 and networking/v1 packages here.
+	Deployment    map[string]interface{}
+	Service       map[string]interface{}
+	NetworkPolicy map[string]interface{}
+
+	// Secrets holds every corev1.Secret referenced from Server/Store (TLS
+	// cert/key, database password, backup/KMS key material), keyed by
+	// secret name, resolved by Resolve before rules run
+	Secrets map[string]*corev1.Secret
+
+	// ConfigMaps holds every corev1.ConfigMap referenced from Server/Store,
+	// keyed by name, resolved by Resolve before rules run
+	ConfigMaps map[string]*corev1.ConfigMap
+
+	// ServiceMonitorPresent records whether a matching ServiceMonitor was found
+	ServiceMonitorPresent bool
+}
+
+// RulePack returns the ordered set of rules for the given profile. Unknown
+// profiles fall back to ProfileBaseline.
+func RulePack(profile string) []Rule {
+	switch profile {
+	case ProfileRestricted:
+		return append(baselineRules(), restrictedRules()...)
+	case ProfilePCI:
+		return append(append(baselineRules(), restrictedRules()...), pciRules()...)
+	default:
+		return baselineRules()
+	}
+}
+
+func baselineRules() []Rule {
+	return []Rule{
+		{
+			ID:          "CIS-OPENFGA-1.1",
+			Severity:    "high",
+			Description: "Server image must be pinned by digest",
+			Remediation: "Set spec.image to a digest reference (image@sha256:...) instead of a mutable tag",
+			Check: func(t *Target) (string, string, bool) {
+				if t.Server == nil {
+					return "", "", true
+				}
+				pinned := strings.Contains(t.Server.Spec.Image, "@sha256:")
+				observed := "tag"
+				if pinned {
+					observed = "digest"
+				}
+				return observed, "digest", pinned
+			},
+		},
+		{
+			ID:          "CIS-OPENFGA-1.2",
+			Severity:    "critical",
+			Description: "Playground must be disabled outside of development",
+			Remediation: "Set spec.config.playgroundEnabled to false",
+			Check: func(t *Target) (string, string, bool) {
+				if t.Server == nil || t.Server.Spec.Config == nil {
+					return "false", "false", true
+				}
+				enabled := t.Server.Spec.Config.PlaygroundEnabled != nil && *t.Server.Spec.Config.PlaygroundEnabled
+				return boolString(enabled), "false", !enabled
+			},
+		},
+		{
+			ID:          "CIS-OPENFGA-1.3",
+			Severity:    "high",
+			Description: "gRPC must require TLS with a resolvable cert/key pair",
+			Remediation: "Set spec.config.grpcConfig.tlsConfig.enabled to true and point certSecret/keySecret at an existing Secret",
+			Check: func(t *Target) (string, string, bool) {
+				if t.Server == nil || t.Server.Spec.Config == nil || t.Server.Spec.Config.GRPCConfig == nil {
+					return "false", "true", false
+				}
+				tls := t.Server.Spec.Config.GRPCConfig.TLSConfig
+				if tls == nil || tls.Enabled == nil || !*tls.Enabled {
+					return "false", "true", false
+				}
+				resolved := tls.CertSecret != nil && t.Secrets[tls.CertSecret.Name] != nil &&
+					tls.KeySecret != nil && t.Secrets[tls.KeySecret.Name] != nil
+				return boolString(resolved), "true", resolved
+			},
+		},
+		{
+			ID:          "CIS-OPENFGA-1.4",
+			Severity:    "high",
+			Description: "Database connections must not disable SSL",
+			Remediation: "Set spec.database.sslMode to require, verify-ca or verify-full",
+			Check: func(t *Target) (string, string, bool) {
+				if t.Server == nil {
+					return "", "", true
+				}
+				mode := t.Server.Spec.Database.SSLMode
+				return mode, "!disable", mode != "disable"
+			},
+		},
+		{
+			ID:          "CIS-OPENFGA-1.5",
+			Severity:    "medium",
+			Description: "Pods must not run as root",
+			Remediation: "Set spec.securityContext.runAsNonRoot to true",
+			Check: func(t *Target) (string, string, bool) {
+				if t.Deployment == nil {
+					return "unset", "true", false
+				}
+				nonRoot, found, _ := unstructured.NestedBool(t.Deployment, "spec", "template", "spec", "securityContext", "runAsNonRoot")
+				if !found {
+					return "unset", "true", false
+				}
+				return boolString(nonRoot), "true", nonRoot
+			},
+		},
+		{
+			ID:          "CIS-OPENFGA-1.6",
+			Severity:    "medium",
+			Description: "Server pods should declare resource requests and limits",
+			Remediation: "Set spec.resources.requests and spec.resources.limits",
+			Check: func(t *Target) (string, string, bool) {
+				if t.Deployment == nil {
+					return "unset", "true", false
+				}
+				containers, found, _ := unstructured.NestedSlice(t.Deployment, "spec", "template", "spec", "containers")
+				if !found || len(containers) == 0 {
+					return "unset", "true", false
+				}
+				container, ok := containers[0].(map[string]interface{})
+				if !ok {
+					return "unset", "true", false
+				}
+				_, hasRequests, _ := unstructured.NestedMap(container, "resources", "requests")
+				_, hasLimits, _ := unstructured.NestedMap(container, "resources", "limits")
+				set := hasRequests && hasLimits
+				return boolString(set), "true", set
+			},
+		},
+	}
+}
+
+func restrictedRules() []Rule {
+	return []Rule{
+		{
+			ID:          "CIS-OPENFGA-2.1",
+			Severity:    "high",
+			Description: "Store access control must be enabled",
+			Remediation: "Set spec.accessControl.enabled to true on the OpenFGAStore",
+			Check: func(t *Target) (string, string, bool) {
+				if t.Store == nil || t.Store.Spec.AccessControl == nil {
+					return "false", "true", false
+				}
+				enabled := t.Store.Spec.AccessControl.Enabled != nil && *t.Store.Spec.AccessControl.Enabled
+				return boolString(enabled), "true", enabled
+			},
+		},
+		{
+			ID:          "CIS-OPENFGA-2.2",
+			Severity:    "high",
+			Description: "A NetworkPolicy must actually be rendered for the server",
+			Remediation: "Set spec.networkPolicy.enabled to true on the OpenFGAServer and confirm the controller rendered a NetworkPolicy/CiliumNetworkPolicy",
+			Check: func(t *Target) (string, string, bool) {
+				present := len(t.NetworkPolicy) > 0
+				return boolString(present), "true", present
+			},
+		},
+		{
+			ID:          "CIS-OPENFGA-2.3",
+			Severity:    "medium",
+			Description: "Trace sampling rate must not exceed the profile threshold",
+			Remediation: "Lower spec.openTelemetry.samplingRate to 0.25 or below",
+			Check: func(t *Target) (string, string, bool) {
+				if t.Server == nil || t.Server.Spec.OpenTelemetry == nil || t.Server.Spec.OpenTelemetry.SamplingRate == nil {
+					return "", "<=0.25", true
+				}
+				rate := *t.Server.Spec.OpenTelemetry.SamplingRate
+				return floatString(rate), "<=0.25", rate <= 0.25
+			},
+		},
+		{
+			ID:          "CIS-OPENFGA-2.4",
+			Severity:    "medium",
+			Description: "A ServiceMonitor should be present for the server",
+			Remediation: "Enable spec.metrics.prometheusConfig.serviceMonitor on at least one store, or create one manually",
+			Check: func(t *Target) (string, string, bool) {
+				return boolString(t.ServiceMonitorPresent), "true", t.ServiceMonitorPresent
+			},
+		},
+	}
+}
+
+func pciRules() []Rule {
+	return []Rule{
+		{
+			ID:          "CIS-OPENFGA-3.1",
+			Severity:    "critical",
+			Description: "Backup encryption must be enabled",
+			Remediation: "Set spec.backup.encryption.enabled to true on the OpenFGAStore",
+			Check: func(t *Target) (string, string, bool) {
+				if t.Store == nil || t.Store.Spec.Backup == nil || t.Store.Spec.Backup.Encryption == nil {
+					return "false", "true", false
+				}
+				enabled := t.Store.Spec.Backup.Encryption.Enabled != nil && *t.Store.Spec.Backup.Encryption.Enabled
+				return boolString(enabled), "true", enabled
+			},
+		},
+		{
+			ID:          "CIS-OPENFGA-3.2",
+			Severity:    "high",
+			Description: "Retention must be bounded",
+			Remediation: "Set spec.retentionPolicy.tupleRetentionDays to a value <= 3650",
+			Check: func(t *Target) (string, string, bool) {
+				if t.Store == nil || t.Store.Spec.RetentionPolicy == nil || t.Store.Spec.RetentionPolicy.TupleRetentionDays == nil {
+					return "unbounded", "bounded", false
+				}
+				return "bounded", "bounded", true
+			},
+		},
+	}
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+func floatString(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
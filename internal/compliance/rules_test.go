@@ -0,0 +1,226 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+func findRule(t *testing.T, rules []Rule, id string) Rule {
+	t.Helper()
+	for _, r := range rules {
+		if r.ID == id {
+			return r
+		}
+	}
+	t.Fatalf("rule %s not found", id)
+	return Rule{}
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestRuleNonRootInspectsRenderedDeployment(t *testing.T) {
+	rule := findRule(t, baselineRules(), "CIS-OPENFGA-1.5")
+
+	target := &Target{
+		Server: &v1alpha1.OpenFGAServer{
+			// SecurityContext declared on the CRD spec must NOT be enough on
+			// its own - the rule should read the rendered Deployment.
+			Spec: v1alpha1.OpenFGAServerSpec{SecurityContext: &corev1.PodSecurityContext{RunAsNonRoot: boolPtr(true)}},
+		},
+	}
+	if _, _, passed := rule.Check(target); passed {
+		t.Fatalf("expected rule to fail when no Deployment was rendered, even with spec.securityContext set")
+	}
+
+	target.Deployment = map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"securityContext": map[string]interface{}{"runAsNonRoot": true},
+				},
+			},
+		},
+	}
+	if _, _, passed := rule.Check(target); !passed {
+		t.Fatalf("expected rule to pass when the rendered Deployment sets runAsNonRoot")
+	}
+}
+
+func TestRuleResourcesInspectsRenderedContainer(t *testing.T) {
+	rule := findRule(t, baselineRules(), "CIS-OPENFGA-1.6")
+
+	target := &Target{Server: &v1alpha1.OpenFGAServer{}}
+	if _, _, passed := rule.Check(target); passed {
+		t.Fatalf("expected rule to fail with no rendered Deployment")
+	}
+
+	target.Deployment = map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"resources": map[string]interface{}{
+								"requests": map[string]interface{}{"cpu": "100m"},
+								"limits":   map[string]interface{}{"cpu": "200m"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if _, _, passed := rule.Check(target); !passed {
+		t.Fatalf("expected rule to pass when the rendered container sets requests and limits")
+	}
+}
+
+func TestRuleNetworkPolicyRequiresRenderedObject(t *testing.T) {
+	rule := findRule(t, restrictedRules(), "CIS-OPENFGA-2.2")
+
+	enabled := true
+	target := &Target{
+		Server: &v1alpha1.OpenFGAServer{
+			Spec: v1alpha1.OpenFGAServerSpec{NetworkPolicy: &v1alpha1.NetworkPolicyConfig{Enabled: &enabled}},
+		},
+	}
+	if _, _, passed := rule.Check(target); passed {
+		t.Fatalf("expected rule to fail when no NetworkPolicy was actually rendered")
+	}
+
+	target.NetworkPolicy = map[string]interface{}{"spec": map[string]interface{}{}}
+	if _, _, passed := rule.Check(target); !passed {
+		t.Fatalf("expected rule to pass once a NetworkPolicy was rendered")
+	}
+}
+
+func TestRuleGRPCTLSRequiresResolvedSecrets(t *testing.T) {
+	rule := findRule(t, baselineRules(), "CIS-OPENFGA-1.3")
+
+	enabled := true
+	target := &Target{
+		Server: &v1alpha1.OpenFGAServer{
+			Spec: v1alpha1.OpenFGAServerSpec{
+				Config: &v1alpha1.OpenFGAConfig{
+					GRPCConfig: &v1alpha1.GRPCConfig{
+						TLSConfig: &v1alpha1.TLSConfig{
+							Enabled:    &enabled,
+							CertSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "tls-cert"}},
+							KeySecret:  &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "tls-key"}},
+						},
+					},
+				},
+			},
+		},
+		Secrets: map[string]*corev1.Secret{},
+	}
+	if _, _, passed := rule.Check(target); passed {
+		t.Fatalf("expected rule to fail when the referenced secrets haven't resolved")
+	}
+
+	target.Secrets["tls-cert"] = &corev1.Secret{}
+	target.Secrets["tls-key"] = &corev1.Secret{}
+	if _, _, passed := rule.Check(target); !passed {
+		t.Fatalf("expected rule to pass once both secrets resolve")
+	}
+}
+
+type fakeObjectGetter struct {
+	secrets map[string]*corev1.Secret
+}
+
+func (f *fakeObjectGetter) GetSecret(_ context.Context, namespace, name string) (*corev1.Secret, error) {
+	secret, ok := f.secrets[namespace+"/"+name]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s not found", namespace, name)
+	}
+	return secret, nil
+}
+
+func (f *fakeObjectGetter) GetConfigMap(_ context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	return nil, fmt.Errorf("configmap %s/%s not found", namespace, name)
+}
+
+func TestResolvePopulatesSecretsFromServerAndStore(t *testing.T) {
+	enabled := true
+	getter := &fakeObjectGetter{secrets: map[string]*corev1.Secret{
+		"ns/tls-cert": {ObjectMeta: metav1.ObjectMeta{Name: "tls-cert"}},
+		"ns/tls-key":  {ObjectMeta: metav1.ObjectMeta{Name: "tls-key"}},
+		"ns/dek-key":  {ObjectMeta: metav1.ObjectMeta{Name: "dek-key"}},
+	}}
+
+	target := &Target{
+		Server: &v1alpha1.OpenFGAServer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns"},
+			Spec: v1alpha1.OpenFGAServerSpec{
+				Config: &v1alpha1.OpenFGAConfig{
+					GRPCConfig: &v1alpha1.GRPCConfig{
+						TLSConfig: &v1alpha1.TLSConfig{
+							Enabled:    &enabled,
+							CertSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "tls-cert"}},
+							KeySecret:  &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "tls-key"}},
+						},
+					},
+				},
+			},
+		},
+		Store: &v1alpha1.OpenFGAStore{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns"},
+			Spec: v1alpha1.OpenFGAStoreSpec{
+				Backup: &v1alpha1.BackupConfig{
+					Encryption: &v1alpha1.EncryptionConfig{
+						KeySecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "dek-key"}},
+					},
+				},
+			},
+		},
+	}
+
+	if err := Resolve(context.Background(), getter, target); err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	for _, name := range []string{"tls-cert", "tls-key", "dek-key"} {
+		if target.Secrets[name] == nil {
+			t.Fatalf("expected secret %q to be resolved", name)
+		}
+	}
+}
+
+func TestResolveErrorsOnMissingSecret(t *testing.T) {
+	getter := &fakeObjectGetter{secrets: map[string]*corev1.Secret{}}
+	target := &Target{
+		Server: &v1alpha1.OpenFGAServer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns"},
+			Spec: v1alpha1.OpenFGAServerSpec{
+				Database: v1alpha1.DatabaseConfig{
+					PasswordSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}},
+				},
+			},
+		},
+	}
+	if err := Resolve(context.Background(), getter, target); err == nil {
+		t.Fatalf("expected Resolve to return an error for an unresolvable secret")
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compliance
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+// ObjectGetter resolves namespaced Secrets and ConfigMaps referenced by a
+// Target's spec. A controller-runtime client.Client does not implement it
+// itself; the compliance report controller adapts one via clientObjectGetter.
+type ObjectGetter interface {
+	GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error)
+	GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
+}
+
+// Resolve populates target.Secrets with every Secret referenced from
+// target.Server/target.Store (TLS cert/key, database password, backup/KMS
+// key material) so rules can inspect the resolved object - e.g. whether a
+// referenced Secret actually exists - rather than just the presence of a
+// reference. It is a no-op for ConfigMaps today: neither OpenFGAServer nor
+// OpenFGAStore reference one, so target.ConfigMaps is only initialized to an
+// empty map, ready for rules that start doing so without another Resolve
+// signature change.
+func Resolve(ctx context.Context, getter ObjectGetter, target *Target) error {
+	target.Secrets = map[string]*corev1.Secret{}
+	target.ConfigMaps = map[string]*corev1.ConfigMap{}
+
+	if target.Server != nil {
+		ns := target.Server.Namespace
+		refs := []*corev1.SecretKeySelector{target.Server.Spec.Database.PasswordSecret}
+		if cfg := target.Server.Spec.Config; cfg != nil && cfg.GRPCConfig != nil && cfg.GRPCConfig.TLSConfig != nil {
+			refs = append(refs, cfg.GRPCConfig.TLSConfig.CertSecret, cfg.GRPCConfig.TLSConfig.KeySecret)
+		}
+		if err := resolveSecrets(ctx, getter, ns, refs, target.Secrets); err != nil {
+			return err
+		}
+	}
+
+	if target.Store != nil {
+		ns := target.Store.Namespace
+		var refs []*corev1.SecretKeySelector
+		if backup := target.Store.Spec.Backup; backup != nil && backup.Encryption != nil {
+			refs = append(refs, backup.Encryption.KeySecret)
+			if backup.Encryption.PKCS11 != nil {
+				refs = append(refs, backup.Encryption.PKCS11.PINSecret)
+			}
+		}
+		if err := resolveSecrets(ctx, getter, ns, refs, target.Secrets); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resolveSecrets(ctx context.Context, getter ObjectGetter, namespace string, refs []*corev1.SecretKeySelector, out map[string]*corev1.Secret) error {
+	for _, ref := range refs {
+		if ref == nil || ref.Name == "" {
+			continue
+		}
+		if _, ok := out[ref.Name]; ok {
+			continue
+		}
+		secret, err := getter.GetSecret(ctx, namespace, ref.Name)
+		if err != nil {
+			return fmt.Errorf("resolving secret %s/%s: %w", namespace, ref.Name, err)
+		}
+		out[ref.Name] = secret
+	}
+	return nil
+}
+
+// Scan evaluates the rule pack for the given profile against target and
+// returns one ComplianceFinding per rule. It also records a Prometheus
+// counter for every evaluation so pass/fail trends can be alerted on.
+func Scan(profile string, target *Target) []v1alpha1.ComplianceFinding {
+	rules := RulePack(profile)
+	findings := make([]v1alpha1.ComplianceFinding, 0, len(rules))
+
+	for _, rule := range rules {
+		observed, expected, passed := rule.Check(target)
+		RecordResult(rule.ID, profile, passed)
+
+		result := "fail"
+		if passed {
+			result = "pass"
+		}
+
+		findings = append(findings, v1alpha1.ComplianceFinding{
+			RuleID:        rule.ID,
+			Severity:      rule.Severity,
+			ResourceRef:   resourceRef(target),
+			Result:        result,
+			ObservedValue: observed,
+			ExpectedValue: expected,
+			Remediation:   rule.Remediation,
+		})
+	}
+
+	return findings
+}
+
+func resourceRef(target *Target) v1alpha1.ComplianceResourceReference {
+	if target.Store != nil {
+		return v1alpha1.ComplianceResourceReference{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "OpenFGAStore",
+			Name:       target.Store.Name,
+			Namespace:  target.Store.Namespace,
+		}
+	}
+	if target.Server != nil {
+		return v1alpha1.ComplianceResourceReference{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "OpenFGAServer",
+			Name:       target.Server.Name,
+			Namespace:  target.Server.Namespace,
+		}
+	}
+	return v1alpha1.ComplianceResourceReference{}
+}
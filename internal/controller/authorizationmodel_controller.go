@@ -0,0 +1,464 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/abac"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/drift"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/dsl"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/openfga"
+)
+
+const (
+	conditionTypeSchemaCompiled = "SchemaCompiled"
+	conditionTypeRollout        = "Rollout"
+	conditionTypeNoDrift        = "NoDrift"
+
+	defaultHistoryLimit    = 25
+	defaultDriftInterval   = 5 * time.Minute
+	driftProbeObjectSuffix = ":__drift_probe__"
+)
+
+// AuthorizationModelReconciler reconciles an AuthorizationModel by compiling
+// Spec.Schema or Spec.DSL (or, when Spec.RollbackTo is set, re-fetching a
+// prior version's document from the store) into the OpenFGA document,
+// type-checking any ABAC conditions it declares, and writing the result as a
+// new immutable model version in the referenced store. Status.ModelID - the
+// version actually in effect - is then promoted according to Spec.RolloutPolicy
+type AuthorizationModelReconciler struct {
+	client.Client
+	ClientFactory ClientFactory
+}
+
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=authorizationmodels,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=authorizationmodels/status,verbs=get;update;patch
+
+func (r *AuthorizationModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var model v1alpha1.AuthorizationModel
+	if err := r.Get(ctx, req.NamespacedName, &model); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	model.Status.ObservedGeneration = model.Generation
+	now := metav1.Now()
+	model.Status.LastReconcileTime = &now
+
+	cli, storeID, err := resolveStoreClient(ctx, r.Client, r.ClientFactory, model.Spec.StoreRef)
+	if err != nil {
+		log.Error(err, "resolving store client")
+		return r.fail(ctx, &model, "StoreResolutionFailed", err)
+	}
+	model.Status.StoreID = storeID
+
+	if model.Spec.PinnedModelID != "" {
+		return r.applyPin(ctx, &model)
+	}
+
+	schema, document, rolledBackFrom, err := r.resolveDocument(ctx, &model, cli, storeID)
+	if err != nil {
+		if model.Spec.RollbackTo != nil {
+			return r.fail(ctx, &model, "RollbackFailed", err)
+		}
+		return r.fail(ctx, &model, "DSLParseFailed", err)
+	}
+
+	if validationErrs := abac.ValidateConditions(schema.Conditions); len(validationErrs) > 0 {
+		model.Status.ValidationErrors = errStrings(validationErrs)
+		return r.fail(ctx, &model, "ConditionValidationFailed", validationErrs[0])
+	}
+	model.Status.ValidationErrors = nil
+
+	checksum := checksumDocument(document)
+	latest := latestHistoryEntry(model.Status.History)
+	if rolledBackFrom != nil || latest == nil || latest.Checksum != checksum {
+		modelID, err := cli.WriteAuthorizationModel(ctx, storeID, document)
+		if err != nil {
+			return r.fail(ctx, &model, "WriteFailed", err)
+		}
+
+		model.Status.CurrentVersion++
+		model.Status.History = append(model.Status.History, v1alpha1.ModelVersionRecord{
+			Version:        model.Status.CurrentVersion,
+			ModelID:        modelID,
+			SchemaVersion:  model.Spec.SchemaVersion,
+			Checksum:       checksum,
+			AppliedAt:      &now,
+			RolledBackFrom: rolledBackFrom,
+		})
+		model.Status.History = trimHistory(model.Status.History, historyLimit(model.Spec.HistoryLimit))
+	}
+
+	model.Status.CompiledSchema = schema
+	model.Status.ConditionsCount = int32(len(schema.Conditions))
+
+	r.applyRolloutPolicy(&model)
+
+	model.Status.Phase = "Ready"
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:   conditionTypeSchemaCompiled,
+		Status: metav1.ConditionTrue,
+		Reason: "Applied",
+	})
+
+	requeueAfter := r.checkDrift(ctx, log, &model, cli, storeID)
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, r.Status().Update(ctx, &model)
+}
+
+// checkDrift runs the model's DriftDetection probe, if enabled, against the
+// live store and records the outcome as Status.DriftReport and the NoDrift
+// condition. It returns the RequeueAfter the caller should use to schedule
+// the next check - zero when drift detection is disabled, since nothing else
+// in this reconciler needs periodic requeuing.
+func (r *AuthorizationModelReconciler) checkDrift(ctx context.Context, log logr.Logger, model *v1alpha1.AuthorizationModel, cli openfga.Client, storeID string) time.Duration {
+	dd := model.Spec.DriftDetection
+	if dd == nil || dd.Enabled == nil || !*dd.Enabled || model.Status.ModelID == "" || model.Status.CompiledSchema == nil {
+		return 0
+	}
+
+	interval := defaultDriftInterval
+	if dd.Interval != nil {
+		interval = dd.Interval.Duration
+	}
+
+	report, err := r.detectDrift(ctx, cli, storeID, model.Status.ModelID, *model.Status.CompiledSchema)
+	if err != nil {
+		log.Error(err, "detecting drift")
+		return interval
+	}
+	model.Status.DriftReport = &report
+
+	condStatus, reason := metav1.ConditionTrue, "NoDriftDetected"
+	if report.Drifted {
+		condStatus, reason = metav1.ConditionFalse, "DriftDetected"
+		if dd.Remediation == "Reapply" {
+			document, err := json.Marshal(model.Status.CompiledSchema)
+			if err != nil {
+				log.Error(err, "marshaling compiled schema for drift remediation")
+			} else if _, err := cli.WriteAuthorizationModel(ctx, storeID, document); err != nil {
+				log.Error(err, "reapplying schema to remediate drift")
+			} else {
+				reason = "DriftDetectedReapplied"
+			}
+		}
+	}
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:   conditionTypeNoDrift,
+		Status: condStatus,
+		Reason: reason,
+	})
+
+	return interval
+}
+
+// detectDrift issues one Expand call per type#relation pair in
+// driftProbeKeys - the union of schema's own relations and the live model's,
+// so a relation added to the store out-of-band is probed too - against a
+// deterministic synthetic probe object for its type, then compares the
+// observed userset tree shapes to the ones schema implies. Expand's response
+// shape is a function of the authorization model alone, not of stored
+// tuples, so any object of the right type - even one with no tuples written
+// against it - yields the same NodeType a real object would. A single
+// relation's Expand call failing is recorded as drift.NodeTypeExpandFailed
+// rather than aborting the whole check; only a failure to even list the
+// live model's relations is fatal to the check.
+func (r *AuthorizationModelReconciler) detectDrift(ctx context.Context, cli openfga.Client, storeID, modelID string, schema v1alpha1.AuthorizationSchema) (v1alpha1.DriftReport, error) {
+	keys, err := r.driftProbeKeys(ctx, cli, storeID, modelID, schema)
+	if err != nil {
+		return v1alpha1.DriftReport{}, err
+	}
+
+	observed := make(map[string]string, len(keys))
+	for key := range keys {
+		typ, relName, _ := strings.Cut(key, "#")
+		node, err := cli.Expand(ctx, storeID, modelID, typ+driftProbeObjectSuffix, relName)
+		if err != nil {
+			observed[key] = drift.NodeTypeExpandFailed
+			continue
+		}
+		observed[key] = string(node.NodeType)
+	}
+
+	report := drift.Detect(schema, observed)
+	now := metav1.Now()
+	report.CheckedAt = &now
+	return report, nil
+}
+
+// driftProbeKeys returns the "type#relation" keys to probe: every relation
+// schema defines, plus every relation defined by modelID's own document in
+// the live store. The latter is recovered by streaming the store's model
+// history looking for modelID and decoding its document back into an
+// AuthorizationSchema - the format this controller itself writes via
+// resolveDocument - so relations that exist live but were never part of
+// schema (added out-of-band, or left over from a rolled-back version) are
+// still probed instead of being structurally unreachable by detectDrift. A
+// document that doesn't decode as an AuthorizationSchema is skipped rather
+// than treated as an error: this comparison only makes sense for models this
+// controller could have written.
+func (r *AuthorizationModelReconciler) driftProbeKeys(ctx context.Context, cli openfga.Client, storeID, modelID string, schema v1alpha1.AuthorizationSchema) (map[string]struct{}, error) {
+	keys := map[string]struct{}{}
+	for _, td := range schema.TypeDefinitions {
+		for relName := range td.Relations {
+			keys[td.Type+"#"+relName] = struct{}{}
+		}
+	}
+
+	var liveDocument []byte
+	if err := cli.StreamAuthorizationModels(ctx, storeID, func(m openfga.Model) error {
+		if m.ID == modelID {
+			liveDocument = m.Document
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("listing authorization models: %w", err)
+	}
+
+	var live v1alpha1.AuthorizationSchema
+	if liveDocument != nil && json.Unmarshal(liveDocument, &live) == nil {
+		for _, td := range live.TypeDefinitions {
+			for relName := range td.Relations {
+				keys[td.Type+"#"+relName] = struct{}{}
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// resolveDocument returns the AuthorizationSchema and its JSON-encoded
+// document to write for model: the result of compiling Spec.Schema/Spec.DSL,
+// or - when Spec.RollbackTo is set - the document of the matching
+// Status.History entry's model, re-fetched from the store since OpenFGA
+// keeps no other copy of it. The returned *int64 is the version being rolled
+// back from, non-nil only for a rollback
+func (r *AuthorizationModelReconciler) resolveDocument(ctx context.Context, model *v1alpha1.AuthorizationModel, cli openfga.Client, storeID string) (*v1alpha1.AuthorizationSchema, []byte, *int64, error) {
+	if model.Spec.RollbackTo == nil {
+		schema, errs := compileSchema(model)
+		model.Status.DSLParseErrors = errStrings(errs)
+		if len(errs) > 0 {
+			return nil, nil, nil, errs[0]
+		}
+		document, err := json.Marshal(schema)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("marshaling compiled schema: %w", err)
+		}
+		return schema, document, nil, nil
+	}
+
+	model.Status.DSLParseErrors = nil
+
+	targetModelID := model.Spec.RollbackTo.ModelID
+	if targetModelID == "" {
+		entry := findHistoryEntryByVersion(model.Status.History, model.Spec.RollbackTo.Version)
+		if entry == nil {
+			return nil, nil, nil, fmt.Errorf("rollbackTo.version %d not found in status.history", model.Spec.RollbackTo.Version)
+		}
+		targetModelID = entry.ModelID
+	}
+
+	var document []byte
+	if err := cli.StreamAuthorizationModels(ctx, storeID, func(m openfga.Model) error {
+		if m.ID == targetModelID {
+			document = m.Document
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, nil, fmt.Errorf("listing authorization models: %w", err)
+	}
+	if document == nil {
+		return nil, nil, nil, fmt.Errorf("model %q not found in store %q", targetModelID, storeID)
+	}
+
+	var schema v1alpha1.AuthorizationSchema
+	if err := json.Unmarshal(document, &schema); err != nil {
+		return nil, nil, nil, fmt.Errorf("decoding rolled-back document: %w", err)
+	}
+
+	current := model.Status.CurrentVersion
+	return &schema, document, &current, nil
+}
+
+// applyPin is the whole reconcile when Spec.PinnedModelID is set: it bypasses
+// compilation entirely and makes Status.ModelID the pinned ID directly,
+// provided that ID is a version this resource has actually applied before
+func (r *AuthorizationModelReconciler) applyPin(ctx context.Context, model *v1alpha1.AuthorizationModel) (ctrl.Result, error) {
+	entry := findHistoryEntryByModelID(model.Status.History, model.Spec.PinnedModelID)
+	if entry == nil {
+		return r.fail(ctx, model, "PinnedModelNotFound", fmt.Errorf("pinnedModelID %q is not in status.history", model.Spec.PinnedModelID))
+	}
+
+	model.Status.ModelID = entry.ModelID
+	model.Status.AppliedAt = entry.AppliedAt
+	model.Status.Phase = "Ready"
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:   conditionTypeSchemaCompiled,
+		Status: metav1.ConditionTrue,
+		Reason: "Applied",
+	})
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:   conditionTypeRollout,
+		Status: metav1.ConditionTrue,
+		Reason: "Pinned",
+	})
+
+	return ctrl.Result{}, r.Status().Update(ctx, model)
+}
+
+// applyRolloutPolicy promotes Status.ModelID to the most recently written
+// history entry under RolloutPolicy Immediate, or leaves it as-is under
+// Manual/Canary until PinnedModelID names that entry explicitly, recording
+// the outcome as the Rollout condition either way
+func (r *AuthorizationModelReconciler) applyRolloutPolicy(model *v1alpha1.AuthorizationModel) {
+	latest := latestHistoryEntry(model.Status.History)
+	if latest == nil {
+		return
+	}
+
+	policy := model.Spec.RolloutPolicy
+	if policy == "" {
+		policy = "Immediate"
+	}
+
+	if policy == "Immediate" {
+		model.Status.ModelID = latest.ModelID
+		model.Status.AppliedAt = latest.AppliedAt
+	}
+
+	if model.Status.ModelID == latest.ModelID {
+		meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+			Type:   conditionTypeRollout,
+			Status: metav1.ConditionTrue,
+			Reason: "InSync",
+		})
+		return
+	}
+
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:    conditionTypeRollout,
+		Status:  metav1.ConditionFalse,
+		Reason:  "PendingPromotion",
+		Message: fmt.Sprintf("version %d (model %s) is compiled and written but awaiting promotion under the %s rollout policy", latest.Version, latest.ModelID, policy),
+	})
+}
+
+// fail records why model could not be applied this reconcile in Status and
+// returns only the status update's error, not cause, so controller-runtime
+// does not requeue immediately - the next spec change, not a tight retry
+// loop, is what would make a DSL/validation/store failure worth re-running.
+func (r *AuthorizationModelReconciler) fail(ctx context.Context, model *v1alpha1.AuthorizationModel, reason string, cause error) (ctrl.Result, error) {
+	model.Status.Phase = "Failed"
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:    conditionTypeSchemaCompiled,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: cause.Error(),
+	})
+	return ctrl.Result{}, r.Status().Update(ctx, model)
+}
+
+// compileSchema returns the AuthorizationSchema to apply for model:
+// Spec.Schema verbatim, or the result of transpiling Spec.DSL. Exactly one
+// of the two is expected to be set, enforced by the CRD's XValidation rule
+func compileSchema(model *v1alpha1.AuthorizationModel) (*v1alpha1.AuthorizationSchema, []error) {
+	if model.Spec.DSL != "" {
+		return dsl.Parse(model.Spec.DSL)
+	}
+	schema := model.Spec.Schema
+	return &schema, nil
+}
+
+func errStrings(errs []error) []string {
+	if len(errs) == 0 {
+		return nil
+	}
+	out := make([]string, len(errs))
+	for i, err := range errs {
+		out[i] = err.Error()
+	}
+	return out
+}
+
+func checksumDocument(document []byte) string {
+	sum := sha256.Sum256(document)
+	return hex.EncodeToString(sum[:])
+}
+
+func historyLimit(limit *int32) int {
+	if limit == nil {
+		return defaultHistoryLimit
+	}
+	return int(*limit)
+}
+
+// trimHistory drops the oldest entries once history exceeds limit. History
+// is always appended oldest-last, so the newest limit entries are the tail
+func trimHistory(history []v1alpha1.ModelVersionRecord, limit int) []v1alpha1.ModelVersionRecord {
+	if len(history) <= limit {
+		return history
+	}
+	return history[len(history)-limit:]
+}
+
+func latestHistoryEntry(history []v1alpha1.ModelVersionRecord) *v1alpha1.ModelVersionRecord {
+	if len(history) == 0 {
+		return nil
+	}
+	return &history[len(history)-1]
+}
+
+func findHistoryEntryByVersion(history []v1alpha1.ModelVersionRecord, version int64) *v1alpha1.ModelVersionRecord {
+	for i := range history {
+		if history[i].Version == version {
+			return &history[i]
+		}
+	}
+	return nil
+}
+
+func findHistoryEntryByModelID(history []v1alpha1.ModelVersionRecord, modelID string) *v1alpha1.ModelVersionRecord {
+	for i := range history {
+		if history[i].ModelID == modelID {
+			return &history[i]
+		}
+	}
+	return nil
+}
+
+func (r *AuthorizationModelReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.AuthorizationModel{}).
+		Complete(r)
+}
@@ -0,0 +1,378 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/drift"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/openfga"
+)
+
+func modelWithSchema(name string) *v1alpha1.AuthorizationModel {
+	return &v1alpha1.AuthorizationModel{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1alpha1.AuthorizationModelSpec{
+			StoreRef: v1alpha1.StoreReference{
+				StoreID:   "store-1",
+				ServerRef: v1alpha1.ServerReference{Name: "server", Endpoint: "https://fga.example.com"},
+			},
+			Schema: v1alpha1.AuthorizationSchema{
+				TypeDefinitions: []v1alpha1.TypeDefinition{
+					{Type: "user"},
+					{Type: "document", Relations: map[string]v1alpha1.Relation{
+						"viewer": {This: &v1alpha1.RelationReference{Type: "user"}},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func reconcileModel(t *testing.T, c client.Client, cli *fakeOpenFGAClient, model *v1alpha1.AuthorizationModel) v1alpha1.AuthorizationModel {
+	t.Helper()
+	r := &AuthorizationModelReconciler{Client: c, ClientFactory: factoryReturning(cli)}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(model)}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	var got v1alpha1.AuthorizationModel
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(model), &got); err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	return got
+}
+
+func TestAuthorizationModelReconcilerAppliesSchema(t *testing.T) {
+	model := modelWithSchema("model")
+	c := newFakeClient(t, model)
+	cli := &fakeOpenFGAClient{}
+
+	got := reconcileModel(t, c, cli, model)
+
+	if got.Status.Phase != "Ready" {
+		t.Fatalf("expected phase Ready, got %q", got.Status.Phase)
+	}
+	if got.Status.ModelID == "" {
+		t.Fatalf("expected a ModelID to be recorded")
+	}
+	if len(cli.writtenModels) != 1 {
+		t.Fatalf("expected exactly one WriteAuthorizationModel call, got %d", len(cli.writtenModels))
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionTypeSchemaCompiled)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Fatalf("expected SchemaCompiled=True, got %+v", cond)
+	}
+}
+
+func TestAuthorizationModelReconcilerTranspilesDSL(t *testing.T) {
+	model := modelWithSchema("model")
+	model.Spec.Schema = v1alpha1.AuthorizationSchema{}
+	model.Spec.DSL = "type user\ntype document\n  relations\n    define viewer: [user]\n"
+	c := newFakeClient(t, model)
+	cli := &fakeOpenFGAClient{}
+
+	got := reconcileModel(t, c, cli, model)
+
+	if got.Status.Phase != "Ready" {
+		t.Fatalf("expected phase Ready, got %q", got.Status.Phase)
+	}
+	if got.Status.CompiledSchema == nil || len(got.Status.CompiledSchema.TypeDefinitions) != 2 {
+		t.Fatalf("expected CompiledSchema to reflect the transpiled DSL, got %+v", got.Status.CompiledSchema)
+	}
+}
+
+func TestAuthorizationModelReconcilerRejectsInvalidDSL(t *testing.T) {
+	model := modelWithSchema("model")
+	model.Spec.Schema = v1alpha1.AuthorizationSchema{}
+	model.Spec.DSL = "type\n"
+	c := newFakeClient(t, model)
+	cli := &fakeOpenFGAClient{}
+
+	got := reconcileModel(t, c, cli, model)
+
+	if got.Status.Phase != "Failed" {
+		t.Fatalf("expected phase Failed, got %q", got.Status.Phase)
+	}
+	if len(got.Status.DSLParseErrors) == 0 {
+		t.Fatalf("expected DSLParseErrors to be recorded")
+	}
+	if len(cli.writtenModels) != 0 {
+		t.Fatalf("expected no WriteAuthorizationModel call for invalid DSL")
+	}
+}
+
+func TestAuthorizationModelReconcilerRejectsInvalidCondition(t *testing.T) {
+	model := modelWithSchema("model")
+	model.Spec.Schema.Conditions = map[string]v1alpha1.Condition{
+		"broken": {Expression: "request_time <"},
+	}
+	c := newFakeClient(t, model)
+	cli := &fakeOpenFGAClient{}
+
+	got := reconcileModel(t, c, cli, model)
+
+	if got.Status.Phase != "Failed" {
+		t.Fatalf("expected phase Failed, got %q", got.Status.Phase)
+	}
+	if len(got.Status.ValidationErrors) == 0 {
+		t.Fatalf("expected ValidationErrors to be recorded")
+	}
+}
+
+func TestAuthorizationModelReconcilerSkipsWriteWhenUnchanged(t *testing.T) {
+	model := modelWithSchema("model")
+	c := newFakeClient(t, model)
+	cli := &fakeOpenFGAClient{}
+
+	first := reconcileModel(t, c, cli, model)
+	if len(cli.writtenModels) != 1 {
+		t.Fatalf("expected one write after the first reconcile, got %d", len(cli.writtenModels))
+	}
+
+	model.ResourceVersion = first.ResourceVersion
+	second := reconcileModel(t, c, cli, model)
+
+	if len(cli.writtenModels) != 1 {
+		t.Fatalf("expected no additional write when the compiled schema is unchanged, got %d writes", len(cli.writtenModels))
+	}
+	if second.Status.ModelID != first.Status.ModelID {
+		t.Fatalf("expected ModelID to stay %q, got %q", first.Status.ModelID, second.Status.ModelID)
+	}
+	if second.Status.CurrentVersion != 1 {
+		t.Fatalf("expected CurrentVersion to stay at 1, got %d", second.Status.CurrentVersion)
+	}
+}
+
+func TestAuthorizationModelReconcilerHistoryLimitTrimsOldestFirst(t *testing.T) {
+	model := modelWithSchema("model")
+	limit := int32(2)
+	model.Spec.HistoryLimit = &limit
+	c := newFakeClient(t, model)
+	cli := &fakeOpenFGAClient{}
+
+	var got v1alpha1.AuthorizationModel
+	for _, relation := range []string{"viewer", "editor", "owner"} {
+		var current v1alpha1.AuthorizationModel
+		if err := c.Get(context.Background(), client.ObjectKeyFromObject(model), &current); err != nil {
+			t.Fatalf("Get returned error: %v", err)
+		}
+		current.Spec.Schema.TypeDefinitions[1].Relations[relation] = v1alpha1.Relation{This: &v1alpha1.RelationReference{Type: "user"}}
+		if err := c.Update(context.Background(), &current); err != nil {
+			t.Fatalf("Update returned error: %v", err)
+		}
+		got = reconcileModel(t, c, cli, &current)
+	}
+
+	if len(got.Status.History) != 2 {
+		t.Fatalf("expected history trimmed to 2 entries, got %d", len(got.Status.History))
+	}
+	if got.Status.History[0].Version != 2 || got.Status.History[1].Version != 3 {
+		t.Fatalf("expected the oldest entry to be pruned first, got versions %d,%d", got.Status.History[0].Version, got.Status.History[1].Version)
+	}
+}
+
+func TestAuthorizationModelReconcilerRolloutPolicyManualWithholdsPromotion(t *testing.T) {
+	model := modelWithSchema("model")
+	model.Spec.RolloutPolicy = "Manual"
+	c := newFakeClient(t, model)
+	cli := &fakeOpenFGAClient{}
+
+	got := reconcileModel(t, c, cli, model)
+
+	if got.Status.Phase != "Ready" {
+		t.Fatalf("expected phase Ready, got %q", got.Status.Phase)
+	}
+	if got.Status.ModelID != "" {
+		t.Fatalf("expected ModelID to stay unpromoted under Manual rollout, got %q", got.Status.ModelID)
+	}
+	if len(got.Status.History) != 1 {
+		t.Fatalf("expected the version to still be written to history, got %d entries", len(got.Status.History))
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionTypeRollout)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "PendingPromotion" {
+		t.Fatalf("expected Rollout=False/PendingPromotion, got %+v", cond)
+	}
+}
+
+func TestAuthorizationModelReconcilerPinnedModelIDPromotesHistoryEntry(t *testing.T) {
+	model := modelWithSchema("model")
+	model.Spec.RolloutPolicy = "Manual"
+	c := newFakeClient(t, model)
+	cli := &fakeOpenFGAClient{}
+
+	staged := reconcileModel(t, c, cli, model)
+	pinnedID := staged.Status.History[0].ModelID
+
+	model.ResourceVersion = staged.ResourceVersion
+	model.Spec.PinnedModelID = pinnedID
+	if err := c.Update(context.Background(), model); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	got := reconcileModel(t, c, cli, model)
+
+	if got.Status.ModelID != pinnedID {
+		t.Fatalf("expected ModelID promoted to pinned %q, got %q", pinnedID, got.Status.ModelID)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionTypeRollout)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != "Pinned" {
+		t.Fatalf("expected Rollout=True/Pinned, got %+v", cond)
+	}
+}
+
+func TestAuthorizationModelReconcilerRollbackRewritesPriorDocument(t *testing.T) {
+	model := modelWithSchema("model")
+	c := newFakeClient(t, model)
+	cli := &fakeOpenFGAClient{}
+
+	v1 := reconcileModel(t, c, cli, model)
+	v1ModelID := v1.Status.ModelID
+
+	model.ResourceVersion = v1.ResourceVersion
+	model.Spec.Schema.TypeDefinitions[1].Relations["editor"] = v1alpha1.Relation{This: &v1alpha1.RelationReference{Type: "user"}}
+	if err := c.Update(context.Background(), model); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	v2 := reconcileModel(t, c, cli, model)
+	if v2.Status.ModelID == v1ModelID {
+		t.Fatalf("expected a new ModelID for the changed schema")
+	}
+
+	model.ResourceVersion = v2.ResourceVersion
+	model.Spec.RollbackTo = &v1alpha1.ModelVersionReference{Version: 1}
+	if err := c.Update(context.Background(), model); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	got := reconcileModel(t, c, cli, model)
+
+	if len(got.Status.History) != 3 {
+		t.Fatalf("expected rollback to append a third history entry, got %d", len(got.Status.History))
+	}
+	rolledBack := got.Status.History[2]
+	if rolledBack.RolledBackFrom == nil || *rolledBack.RolledBackFrom != 2 {
+		t.Fatalf("expected RolledBackFrom=2, got %+v", rolledBack.RolledBackFrom)
+	}
+	if got.Status.CompiledSchema == nil || len(got.Status.CompiledSchema.TypeDefinitions[1].Relations) != 1 {
+		t.Fatalf("expected CompiledSchema to reflect the v1 document, got %+v", got.Status.CompiledSchema)
+	}
+}
+
+func TestAuthorizationModelReconcilerDriftDetectionReportsMismatch(t *testing.T) {
+	enabled := true
+	model := modelWithSchema("model")
+	model.Spec.DriftDetection = &v1alpha1.DriftDetectionConfig{Enabled: &enabled}
+	c := newFakeClient(t, model)
+	cli := &fakeOpenFGAClient{expandResult: openfga.UsersetTreeNode{NodeType: openfga.NodeUnion}}
+
+	got := reconcileModel(t, c, cli, model)
+
+	if got.Status.DriftReport == nil || !got.Status.DriftReport.Drifted {
+		t.Fatalf("expected DriftReport.Drifted=true, got %+v", got.Status.DriftReport)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionTypeNoDrift)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "DriftDetected" {
+		t.Fatalf("expected NoDrift=False/DriftDetected, got %+v", cond)
+	}
+}
+
+func TestAuthorizationModelReconcilerDriftDetectionReapplyOnMismatch(t *testing.T) {
+	enabled := true
+	model := modelWithSchema("model")
+	model.Spec.DriftDetection = &v1alpha1.DriftDetectionConfig{Enabled: &enabled, Remediation: "Reapply"}
+	c := newFakeClient(t, model)
+	cli := &fakeOpenFGAClient{expandResult: openfga.UsersetTreeNode{NodeType: openfga.NodeUnion}}
+
+	got := reconcileModel(t, c, cli, model)
+
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionTypeNoDrift)
+	if cond == nil || cond.Reason != "DriftDetectedReapplied" {
+		t.Fatalf("expected NoDrift reason DriftDetectedReapplied, got %+v", cond)
+	}
+	if len(cli.writtenModels) != 2 {
+		t.Fatalf("expected the initial write plus one remediation rewrite, got %d", len(cli.writtenModels))
+	}
+}
+
+func TestAuthorizationModelReconcilerDriftDetectionDiscoversLiveOnlyRelation(t *testing.T) {
+	enabled := true
+	model := modelWithSchema("model")
+	model.Spec.DriftDetection = &v1alpha1.DriftDetectionConfig{Enabled: &enabled}
+	c := newFakeClient(t, model)
+	cli := &fakeOpenFGAClient{expandResult: openfga.UsersetTreeNode{NodeType: openfga.NodeLeaf}}
+
+	reconcileModel(t, c, cli, model)
+
+	liveSchema := v1alpha1.AuthorizationSchema{
+		TypeDefinitions: []v1alpha1.TypeDefinition{
+			{Type: "document", Relations: map[string]v1alpha1.Relation{
+				"viewer": {This: &v1alpha1.RelationReference{Type: "user"}},
+				"editor": {This: &v1alpha1.RelationReference{Type: "user"}},
+			}},
+		},
+	}
+	document, err := json.Marshal(liveSchema)
+	if err != nil {
+		t.Fatalf("marshaling live schema: %v", err)
+	}
+	cli.writtenModels[0].Document = document
+
+	got := reconcileModel(t, c, cli, model)
+
+	if got.Status.DriftReport == nil || !got.Status.DriftReport.Drifted {
+		t.Fatalf("expected drift from the live-only editor relation, got %+v", got.Status.DriftReport)
+	}
+	var found *v1alpha1.RelationDrift
+	for i, m := range got.Status.DriftReport.Mismatches {
+		if m.Type == "document" && m.Relation == "editor" {
+			found = &got.Status.DriftReport.Mismatches[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("expected detectDrift to discover and probe the live-only editor relation, got %+v", got.Status.DriftReport.Mismatches)
+	}
+	if found.ExpectedNodeType != drift.NodeTypeUndefined {
+		t.Fatalf("expected editor's ExpectedNodeType to be Undefined since spec.schema doesn't define it, got %+v", found)
+	}
+}
+
+func TestAuthorizationModelReconcilerDriftDetectionSurvivesExpandError(t *testing.T) {
+	enabled := true
+	model := modelWithSchema("model")
+	model.Spec.DriftDetection = &v1alpha1.DriftDetectionConfig{Enabled: &enabled}
+	c := newFakeClient(t, model)
+	cli := &fakeOpenFGAClient{expandErr: fmt.Errorf("expand unavailable")}
+
+	got := reconcileModel(t, c, cli, model)
+
+	if got.Status.Phase != "Ready" {
+		t.Fatalf("expected phase Ready even when every Expand call fails, got %q", got.Status.Phase)
+	}
+	if got.Status.DriftReport == nil || !got.Status.DriftReport.Drifted {
+		t.Fatalf("expected a DriftReport recording the Expand failure instead of an aborted check, got %+v", got.Status.DriftReport)
+	}
+	if len(got.Status.DriftReport.Mismatches) != 1 || got.Status.DriftReport.Mismatches[0].ObservedNodeType != drift.NodeTypeExpandFailed {
+		t.Fatalf("expected a single ExpandFailed mismatch, got %+v", got.Status.DriftReport.Mismatches)
+	}
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/openfga"
+)
+
+const conditionTypeQuerySatisfied = "Satisfied"
+
+// AuthorizationQueryReconciler evaluates an AuthorizationQuery against its
+// referenced OpenFGA store, comparing the live result to the expectation
+// declared on Spec, and records both in Status for CI gating or alerting.
+//
+// Spec.Schedule is not wired into requeueing yet - honoring a cron
+// expression needs a parser this build doesn't depend on - so today a query
+// is only re-evaluated when the AuthorizationQuery object itself changes
+// (its spec or its status) and is reconciled again.
+type AuthorizationQueryReconciler struct {
+	client.Client
+	ClientFactory ClientFactory
+	Recorder      record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=authorizationqueries,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=authorizationqueries/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=authorizationmodels,verbs=get
+
+func (r *AuthorizationQueryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var query v1alpha1.AuthorizationQuery
+	if err := r.Get(ctx, req.NamespacedName, &query); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	cli, storeID, err := resolveStoreClient(ctx, r.Client, r.ClientFactory, query.Spec.StoreRef)
+	if err != nil {
+		log.Error(err, "resolving store client")
+		return r.fail(ctx, &query, err)
+	}
+
+	modelID, err := r.resolveModelID(ctx, query.Namespace, query.Spec.AuthorizationModelRef)
+	if err != nil {
+		log.Error(err, "resolving authorization model")
+		return r.fail(ctx, &query, err)
+	}
+
+	result, actual, satisfied, err := evaluateQuery(ctx, cli, storeID, modelID, query.Spec)
+	if err != nil {
+		log.Error(err, "evaluating query")
+		return r.fail(ctx, &query, err)
+	}
+
+	now := metav1.Now()
+	query.Status.Phase = "Evaluated"
+	query.Status.LastEvaluationTime = &now
+	query.Status.ActualResult = actual
+	query.Status.Result = result
+	query.Status.ResultHash = hashResult(result)
+	query.Status.Satisfied = &satisfied
+
+	condStatus, reason := metav1.ConditionTrue, "ResultMatchesExpectation"
+	if !satisfied {
+		condStatus, reason = metav1.ConditionFalse, "ResultMismatch"
+		if r.Recorder != nil {
+			r.Recorder.Event(&query, corev1.EventTypeWarning, reason, actual)
+		}
+	}
+	meta.SetStatusCondition(&query.Status.Conditions, metav1.Condition{
+		Type:    conditionTypeQuerySatisfied,
+		Status:  condStatus,
+		Reason:  reason,
+		Message: actual,
+	})
+
+	return ctrl.Result{}, r.Status().Update(ctx, &query)
+}
+
+func (r *AuthorizationQueryReconciler) fail(ctx context.Context, query *v1alpha1.AuthorizationQuery, cause error) (ctrl.Result, error) {
+	query.Status.Phase = "Failed"
+	meta.SetStatusCondition(&query.Status.Conditions, metav1.Condition{
+		Type:    conditionTypeQuerySatisfied,
+		Status:  metav1.ConditionFalse,
+		Reason:  "EvaluationFailed",
+		Message: cause.Error(),
+	})
+	return ctrl.Result{}, r.Status().Update(ctx, query)
+}
+
+// resolveModelID follows ref to an OpenFGA model ID: ref.ModelID directly,
+// or the latest Status.ModelID of the AuthorizationModel it names. A nil
+// ref means "use the store's latest applied model", which this build
+// expresses as an empty modelID - most OpenFGA deployments default Check
+// and friends to the store's latest model when none is specified.
+func (r *AuthorizationQueryReconciler) resolveModelID(ctx context.Context, namespace string, ref *v1alpha1.AuthorizationModelReference) (string, error) {
+	if ref == nil {
+		return "", nil
+	}
+	if ref.ModelID != "" {
+		return ref.ModelID, nil
+	}
+
+	ns := ref.Namespace
+	if ns == "" {
+		ns = namespace
+	}
+
+	var model v1alpha1.AuthorizationModel
+	if err := r.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: ns}, &model); err != nil {
+		return "", fmt.Errorf("resolving AuthorizationModel %s/%s: %w", ns, ref.Name, err)
+	}
+	if model.Status.ModelID == "" {
+		return "", fmt.Errorf("AuthorizationModel %s/%s has no status.modelID yet", ns, ref.Name)
+	}
+	return model.Status.ModelID, nil
+}
+
+// evaluateQuery runs the OpenFGA API call selected by spec.Type and compares
+// it against the expectation declared alongside it, returning the typed
+// result, a human-readable rendering of it, and whether it matched.
+func evaluateQuery(ctx context.Context, cli openfga.Client, storeID, modelID string, spec v1alpha1.AuthorizationQuerySpec) (*v1alpha1.QueryResult, string, bool, error) {
+	switch spec.Type {
+	case "Check":
+		return evaluateCheck(ctx, cli, storeID, modelID, spec.Check)
+	case "Expand":
+		return evaluateExpand(ctx, cli, storeID, modelID, spec.Expand)
+	case "ListObjects":
+		return evaluateListObjects(ctx, cli, storeID, modelID, spec.ListObjects)
+	case "ListUsers":
+		return evaluateListUsers(ctx, cli, storeID, modelID, spec.ListUsers)
+	default:
+		return nil, "", false, fmt.Errorf("unknown query type %q", spec.Type)
+	}
+}
+
+func evaluateCheck(ctx context.Context, cli openfga.Client, storeID, modelID string, q *v1alpha1.CheckQuery) (*v1alpha1.QueryResult, string, bool, error) {
+	if q == nil {
+		return nil, "", false, fmt.Errorf("spec.check must be set when spec.type is Check")
+	}
+	if len(q.ContextualTuples) > 0 {
+		return nil, "", false, fmt.Errorf("check queries with contextualTuples are not yet supported: openfga.Client.Check has no contextual-tuples parameter in this build")
+	}
+
+	ctxValues := make(map[string]interface{}, len(q.Context))
+	for k, v := range q.Context {
+		ctxValues[k] = v
+	}
+
+	allowed, err := cli.Check(ctx, storeID, modelID, q.User, q.Relation, q.Object, ctxValues)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("Check: %w", err)
+	}
+
+	return &v1alpha1.QueryResult{Allowed: &allowed}, fmt.Sprintf("allowed=%t", allowed), allowed == q.ExpectAllowed, nil
+}
+
+func evaluateExpand(ctx context.Context, cli openfga.Client, storeID, modelID string, q *v1alpha1.ExpandQuery) (*v1alpha1.QueryResult, string, bool, error) {
+	if q == nil {
+		return nil, "", false, fmt.Errorf("spec.expand must be set when spec.type is Expand")
+	}
+
+	tree, err := cli.Expand(ctx, storeID, modelID, q.Object, q.Relation)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("Expand: %w", err)
+	}
+
+	leaves := leafUsers(tree)
+	return &v1alpha1.QueryResult{Leaves: leaves}, fmt.Sprintf("leaves=%v", leaves), stringSetEqual(leaves, q.ExpectLeaves), nil
+}
+
+// leafUsers flattens every NodeLeaf's Users across the tree into a single,
+// deduplicated list
+func leafUsers(node openfga.UsersetTreeNode) []string {
+	seen := map[string]bool{}
+	var leaves []string
+	var walk func(n openfga.UsersetTreeNode)
+	walk = func(n openfga.UsersetTreeNode) {
+		if n.NodeType == openfga.NodeLeaf {
+			for _, u := range n.Users {
+				if !seen[u] {
+					seen[u] = true
+					leaves = append(leaves, u)
+				}
+			}
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(node)
+	return leaves
+}
+
+func evaluateListObjects(ctx context.Context, cli openfga.Client, storeID, modelID string, q *v1alpha1.ListObjectsQuery) (*v1alpha1.QueryResult, string, bool, error) {
+	if q == nil {
+		return nil, "", false, fmt.Errorf("spec.listObjects must be set when spec.type is ListObjects")
+	}
+	if len(q.Context) > 0 {
+		return nil, "", false, fmt.Errorf("listObjects queries with context are not yet supported: openfga.Client.ListObjects has no context parameter in this build")
+	}
+
+	objects, err := cli.ListObjects(ctx, storeID, modelID, q.User, q.Relation, q.Type)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("ListObjects: %w", err)
+	}
+
+	return &v1alpha1.QueryResult{Objects: objects}, fmt.Sprintf("objects=%v", objects), stringSetEqual(objects, q.ExpectObjects), nil
+}
+
+func evaluateListUsers(ctx context.Context, cli openfga.Client, storeID, modelID string, q *v1alpha1.ListUsersQuery) (*v1alpha1.QueryResult, string, bool, error) {
+	if q == nil {
+		return nil, "", false, fmt.Errorf("spec.listUsers must be set when spec.type is ListUsers")
+	}
+
+	users, err := cli.ListUsers(ctx, storeID, modelID, q.Object, q.Relation, q.UserFilters)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("ListUsers: %w", err)
+	}
+
+	return &v1alpha1.QueryResult{Users: users}, fmt.Sprintf("users=%v", users), stringSetEqual(users, q.ExpectUsers), nil
+}
+
+// stringSetEqual compares got and want as order-independent sets.
+func stringSetEqual(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	g := append([]string(nil), got...)
+	w := append([]string(nil), want...)
+	sort.Strings(g)
+	sort.Strings(w)
+	for i := range g {
+		if g[i] != w[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hashResult returns a content hash of result, or "" when result is nil.
+func hashResult(result *v1alpha1.QueryResult) string {
+	if result == nil {
+		return ""
+	}
+	data, _ := json.Marshal(result)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (r *AuthorizationQueryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.AuthorizationQuery{}).
+		Complete(r)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/openfga"
+)
+
+func checkQuery(name string, expectAllowed bool) *v1alpha1.AuthorizationQuery {
+	return &v1alpha1.AuthorizationQuery{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1alpha1.AuthorizationQuerySpec{
+			StoreRef: v1alpha1.StoreReference{
+				StoreID:   "store-1",
+				ServerRef: v1alpha1.ServerReference{Name: "server", Endpoint: "https://fga.example.com"},
+			},
+			Type: "Check",
+			Check: &v1alpha1.CheckQuery{
+				User:          "user:anne",
+				Relation:      "viewer",
+				Object:        "document:roadmap",
+				ExpectAllowed: expectAllowed,
+			},
+		},
+	}
+}
+
+func reconcileQuery(t *testing.T, c client.Client, cli *fakeOpenFGAClient, query *v1alpha1.AuthorizationQuery) v1alpha1.AuthorizationQuery {
+	t.Helper()
+	r := &AuthorizationQueryReconciler{Client: c, ClientFactory: factoryReturning(cli)}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(query)}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	var got v1alpha1.AuthorizationQuery
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(query), &got); err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	return got
+}
+
+func TestAuthorizationQueryReconcilerSatisfiedCheck(t *testing.T) {
+	query := checkQuery("query", true)
+	c := newFakeClient(t, query)
+	cli := &fakeOpenFGAClient{checkResult: true}
+
+	got := reconcileQuery(t, c, cli, query)
+
+	if got.Status.Phase != "Evaluated" {
+		t.Fatalf("expected phase Evaluated, got %q", got.Status.Phase)
+	}
+	if got.Status.Satisfied == nil || !*got.Status.Satisfied {
+		t.Fatalf("expected Satisfied=true, got %+v", got.Status.Satisfied)
+	}
+	if got.Status.Result == nil || got.Status.Result.Allowed == nil || !*got.Status.Result.Allowed {
+		t.Fatalf("expected Result.Allowed=true, got %+v", got.Status.Result)
+	}
+	if got.Status.ResultHash == "" {
+		t.Fatalf("expected a non-empty ResultHash")
+	}
+}
+
+func TestAuthorizationQueryReconcilerUnsatisfiedCheck(t *testing.T) {
+	query := checkQuery("query", true)
+	c := newFakeClient(t, query)
+	cli := &fakeOpenFGAClient{checkResult: false}
+
+	got := reconcileQuery(t, c, cli, query)
+
+	if got.Status.Satisfied == nil || *got.Status.Satisfied {
+		t.Fatalf("expected Satisfied=false, got %+v", got.Status.Satisfied)
+	}
+}
+
+func TestAuthorizationQueryReconcilerListObjectsOrderIndependent(t *testing.T) {
+	query := &v1alpha1.AuthorizationQuery{
+		ObjectMeta: metav1.ObjectMeta{Name: "query", Namespace: "default"},
+		Spec: v1alpha1.AuthorizationQuerySpec{
+			StoreRef: v1alpha1.StoreReference{
+				StoreID:   "store-1",
+				ServerRef: v1alpha1.ServerReference{Name: "server", Endpoint: "https://fga.example.com"},
+			},
+			Type: "ListObjects",
+			ListObjects: &v1alpha1.ListObjectsQuery{
+				User:          "user:anne",
+				Relation:      "viewer",
+				Type:          "document",
+				ExpectObjects: []string{"document:1", "document:2"},
+			},
+		},
+	}
+	c := newFakeClient(t, query)
+	cli := &fakeOpenFGAClient{listObjectsResult: []string{"document:2", "document:1"}}
+
+	got := reconcileQuery(t, c, cli, query)
+
+	if got.Status.Satisfied == nil || !*got.Status.Satisfied {
+		t.Fatalf("expected Satisfied=true for a reordered but equal set, got %+v", got.Status.Satisfied)
+	}
+}
+
+func expandQuery(name string, expectLeaves []string) *v1alpha1.AuthorizationQuery {
+	return &v1alpha1.AuthorizationQuery{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1alpha1.AuthorizationQuerySpec{
+			StoreRef: v1alpha1.StoreReference{
+				StoreID:   "store-1",
+				ServerRef: v1alpha1.ServerReference{Name: "server", Endpoint: "https://fga.example.com"},
+			},
+			Type: "Expand",
+			Expand: &v1alpha1.ExpandQuery{
+				Relation:     "viewer",
+				Object:       "document:roadmap",
+				ExpectLeaves: expectLeaves,
+			},
+		},
+	}
+}
+
+func TestAuthorizationQueryReconcilerExpandSatisfiedAcrossNestedUnion(t *testing.T) {
+	query := expandQuery("query", []string{"user:anne", "user:bob"})
+	c := newFakeClient(t, query)
+	cli := &fakeOpenFGAClient{
+		expandResult: openfga.UsersetTreeNode{
+			NodeType: openfga.NodeUnion,
+			Children: []openfga.UsersetTreeNode{
+				{NodeType: openfga.NodeLeaf, Users: []string{"user:bob"}},
+				{NodeType: openfga.NodeLeaf, Users: []string{"user:anne"}},
+			},
+		},
+	}
+
+	got := reconcileQuery(t, c, cli, query)
+
+	if got.Status.Phase != "Evaluated" {
+		t.Fatalf("expected phase Evaluated, got %q", got.Status.Phase)
+	}
+	if got.Status.Satisfied == nil || !*got.Status.Satisfied {
+		t.Fatalf("expected Satisfied=true for a reordered but equal leaf set, got %+v", got.Status.Satisfied)
+	}
+	if got.Status.Result == nil || len(got.Status.Result.Leaves) != 2 {
+		t.Fatalf("expected Result.Leaves to contain both leaf users, got %+v", got.Status.Result)
+	}
+}
+
+func TestAuthorizationQueryReconcilerExpandUnsatisfiedOnMissingLeaf(t *testing.T) {
+	query := expandQuery("query", []string{"user:anne", "user:bob"})
+	c := newFakeClient(t, query)
+	cli := &fakeOpenFGAClient{
+		expandResult: openfga.UsersetTreeNode{
+			NodeType: openfga.NodeLeaf,
+			Users:    []string{"user:anne"},
+		},
+	}
+
+	got := reconcileQuery(t, c, cli, query)
+
+	if got.Status.Satisfied == nil || *got.Status.Satisfied {
+		t.Fatalf("expected Satisfied=false, got %+v", got.Status.Satisfied)
+	}
+}
+
+func TestAuthorizationQueryReconcilerRejectsCheckWithContextualTuples(t *testing.T) {
+	query := checkQuery("query", true)
+	query.Spec.Check.ContextualTuples = []v1alpha1.ContextualTuple{
+		{User: "user:anne", Relation: "viewer", Object: "document:roadmap"},
+	}
+	c := newFakeClient(t, query)
+	cli := &fakeOpenFGAClient{checkResult: true}
+
+	got := reconcileQuery(t, c, cli, query)
+
+	if got.Status.Phase != "Failed" {
+		t.Fatalf("expected phase Failed for contextualTuples, got %q", got.Status.Phase)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+func TestS3DestinationWriterWriteAndRead(t *testing.T) {
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			t.Fatalf("request missing Authorization header: %s %s", r.Method, r.URL.Path)
+		}
+		switch r.Method {
+		case http.MethodPut:
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Fatalf("reading request body: %v", err)
+			}
+			objects[r.URL.Path] = body
+			w.WriteHeader(http.StatusOK)
+		case http.MethodGet:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(body)
+		default:
+			t.Fatalf("unexpected method %s", r.Method)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &v1alpha1.S3BackupDestination{
+		Endpoint: server.URL,
+		Bucket:   "backups",
+		Prefix:   "openfga",
+		Region:   "us-east-1",
+	}
+	creds := s3Credentials{accessKeyID: "AKIAEXAMPLE", secretAccessKey: "secret"}
+	w := newS3DestinationWriter(cfg, creds)
+
+	url, err := w.Write(context.Background(), "snapshot-1.tar", []byte("payload"))
+	if err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	if !strings.Contains(url, "/backups/openfga/snapshot-1.tar") {
+		t.Fatalf("expected URL to contain the bucket/prefix/key path, got %q", url)
+	}
+
+	got, err := w.Read(context.Background(), "snapshot-1.tar")
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if string(got) != "payload" {
+		t.Fatalf("expected %q, got %q", "payload", got)
+	}
+}
+
+func TestS3DestinationWriterReadMissingObject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cfg := &v1alpha1.S3BackupDestination{Endpoint: server.URL, Bucket: "backups"}
+	w := newS3DestinationWriter(cfg, s3Credentials{accessKeyID: "AKIAEXAMPLE", secretAccessKey: "secret"})
+
+	if _, err := w.Read(context.Background(), "missing.tar"); err == nil {
+		t.Fatalf("expected an error for a missing object")
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jralmaraz/authcore-openfga-operator/internal/openfga"
+)
+
+// fakeOpenFGAClient is a minimal in-memory openfga.Client shared by this
+// package's reconciler tests. WriteAuthorizationModel assigns sequential IDs
+// so tests can assert on write order as well as write count.
+type fakeOpenFGAClient struct {
+	writtenModels []openfga.Model
+	writeErr      error
+
+	checkResult       bool
+	checkErr          error
+	expandResult      openfga.UsersetTreeNode
+	expandErr         error
+	listObjectsResult []string
+	listObjectsErr    error
+	listUsersResult   []string
+	listUsersErr      error
+}
+
+func (f *fakeOpenFGAClient) StreamTuples(ctx context.Context, storeID string, pageSize int32, fn func([]openfga.Tuple) error) error {
+	return nil
+}
+
+func (f *fakeOpenFGAClient) StreamAuthorizationModels(ctx context.Context, storeID string, fn func(openfga.Model) error) error {
+	for _, m := range f.writtenModels {
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeOpenFGAClient) WriteTuples(ctx context.Context, storeID string, writes, deletes []openfga.Tuple) error {
+	return nil
+}
+
+func (f *fakeOpenFGAClient) WriteAuthorizationModel(ctx context.Context, storeID string, document []byte) (string, error) {
+	if f.writeErr != nil {
+		return "", f.writeErr
+	}
+	id := fmt.Sprintf("model-%d", len(f.writtenModels)+1)
+	f.writtenModels = append(f.writtenModels, openfga.Model{ID: id, Document: document})
+	return id, nil
+}
+
+func (f *fakeOpenFGAClient) Check(ctx context.Context, storeID, modelID, user, relation, object string, context map[string]interface{}) (bool, error) {
+	return f.checkResult, f.checkErr
+}
+
+func (f *fakeOpenFGAClient) Expand(ctx context.Context, storeID, modelID, object, relation string) (openfga.UsersetTreeNode, error) {
+	return f.expandResult, f.expandErr
+}
+
+func (f *fakeOpenFGAClient) ListObjects(ctx context.Context, storeID, modelID, user, relation, objectType string) ([]string, error) {
+	return f.listObjectsResult, f.listObjectsErr
+}
+
+func (f *fakeOpenFGAClient) ListUsers(ctx context.Context, storeID, modelID, object, relation string, userFilters []string) ([]string, error) {
+	return f.listUsersResult, f.listUsersErr
+}
+
+func factoryReturning(cli openfga.Client) ClientFactory {
+	return func(ctx context.Context, endpoint string, opts ClientOptions) (openfga.Client, error) {
+		return cli, nil
+	}
+}
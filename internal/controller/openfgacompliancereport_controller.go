@@ -0,0 +1,180 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/compliance"
+)
+
+const conditionTypeScanCompleted = "ScanCompleted"
+
+// OpenFGAComplianceReportReconciler scans the OpenFGAServer or OpenFGAStore
+// named by Spec.ServerRef/Spec.StoreRef against a profile-selected CIS-style
+// rule pack and records rule-by-rule findings in Status.
+//
+// This build has no controller that generates a Deployment/Service/
+// NetworkPolicy for an OpenFGAServer/OpenFGAStore, so Target.Deployment,
+// Target.Service and Target.NetworkPolicy are always left unresolved here;
+// rules that depend on them (e.g. CIS-OPENFGA-1.5, 1.6 and 2.2) correctly
+// fail rather than silently passing, since compliance.Rule.Check already
+// treats a nil Target field as "not configured".
+//
+// Spec.Schedule is not wired into requeueing yet - honoring a cron
+// expression needs a parser this build doesn't depend on - so today a report
+// is only re-scanned when its own spec changes.
+type OpenFGAComplianceReportReconciler struct {
+	client.Client
+}
+
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=openfgacompliancereports,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=openfgacompliancereports/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=openfgaservers;openfgastores,verbs=get
+// +kubebuilder:rbac:groups="",resources=secrets;configmaps,verbs=get
+
+func (r *OpenFGAComplianceReportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var report v1alpha1.OpenFGAComplianceReport
+	if err := r.Get(ctx, req.NamespacedName, &report); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	target, err := r.resolveTarget(ctx, req.Namespace, report.Spec)
+	if err != nil {
+		log.Error(err, "resolving compliance scan target")
+		return r.fail(ctx, &report, err)
+	}
+
+	if err := compliance.Resolve(ctx, clientObjectGetter{r.Client}, target); err != nil {
+		log.Error(err, "resolving referenced secrets/configmaps")
+		return r.fail(ctx, &report, err)
+	}
+
+	findings := compliance.Scan(report.Spec.Profile, target)
+
+	var passCount, failCount int32
+	for _, f := range findings {
+		switch f.Result {
+		case "pass":
+			passCount++
+		case "fail":
+			failCount++
+		}
+	}
+
+	now := metav1.Now()
+	report.Status.Phase = "Completed"
+	report.Status.LastScanTime = &now
+	report.Status.Findings = findings
+	report.Status.PassCount = passCount
+	report.Status.FailCount = failCount
+
+	condStatus, reason := metav1.ConditionTrue, "NoFailures"
+	if failCount > 0 {
+		condStatus, reason = metav1.ConditionFalse, "FailuresFound"
+	}
+	meta.SetStatusCondition(&report.Status.Conditions, metav1.Condition{
+		Type:    conditionTypeScanCompleted,
+		Status:  condStatus,
+		Reason:  reason,
+		Message: fmt.Sprintf("%d passed, %d failed", passCount, failCount),
+	})
+
+	return ctrl.Result{}, r.Status().Update(ctx, &report)
+}
+
+// resolveTarget fetches the single OpenFGAServer or OpenFGAStore Spec names,
+// populating the corresponding field of a fresh compliance.Target
+func (r *OpenFGAComplianceReportReconciler) resolveTarget(ctx context.Context, namespace string, spec v1alpha1.OpenFGAComplianceReportSpec) (*compliance.Target, error) {
+	target := &compliance.Target{}
+
+	switch {
+	case spec.ServerRef != nil:
+		ns := spec.ServerRef.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+		var server v1alpha1.OpenFGAServer
+		if err := r.Get(ctx, client.ObjectKey{Name: spec.ServerRef.Name, Namespace: ns}, &server); err != nil {
+			return nil, fmt.Errorf("resolving OpenFGAServer %s/%s: %w", ns, spec.ServerRef.Name, err)
+		}
+		target.Server = &server
+	case spec.StoreRef != nil:
+		ns := spec.StoreRef.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+		var store v1alpha1.OpenFGAStore
+		if err := r.Get(ctx, client.ObjectKey{Name: spec.StoreRef.Name, Namespace: ns}, &store); err != nil {
+			return nil, fmt.Errorf("resolving OpenFGAStore %s/%s: %w", ns, spec.StoreRef.Name, err)
+		}
+		target.Store = &store
+	default:
+		return nil, fmt.Errorf("exactly one of spec.serverRef or spec.storeRef must be set")
+	}
+
+	return target, nil
+}
+
+func (r *OpenFGAComplianceReportReconciler) fail(ctx context.Context, report *v1alpha1.OpenFGAComplianceReport, cause error) (ctrl.Result, error) {
+	report.Status.Phase = "Failed"
+	meta.SetStatusCondition(&report.Status.Conditions, metav1.Condition{
+		Type:    conditionTypeScanCompleted,
+		Status:  metav1.ConditionFalse,
+		Reason:  "ScanFailed",
+		Message: cause.Error(),
+	})
+	return ctrl.Result{}, r.Status().Update(ctx, report)
+}
+
+// clientObjectGetter adapts a controller-runtime client.Client to compliance.ObjectGetter
+type clientObjectGetter struct {
+	client.Client
+}
+
+func (g clientObjectGetter) GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	var secret corev1.Secret
+	if err := g.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+func (g clientObjectGetter) GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	var cm corev1.ConfigMap
+	if err := g.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &cm); err != nil {
+		return nil, err
+	}
+	return &cm, nil
+}
+
+func (r *OpenFGAComplianceReportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.OpenFGAComplianceReport{}).
+		Complete(r)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+func reconcileComplianceReport(t *testing.T, c client.Client, report *v1alpha1.OpenFGAComplianceReport) v1alpha1.OpenFGAComplianceReport {
+	t.Helper()
+	r := &OpenFGAComplianceReportReconciler{Client: c}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(report)}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	var got v1alpha1.OpenFGAComplianceReport
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(report), &got); err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	return got
+}
+
+func TestOpenFGAComplianceReportReconcilerScansReferencedStore(t *testing.T) {
+	store := &v1alpha1.OpenFGAStore{
+		ObjectMeta: metav1.ObjectMeta{Name: "store", Namespace: "default"},
+		Spec:       v1alpha1.OpenFGAStoreSpec{ServerRef: v1alpha1.ServerReference{Name: "server"}},
+	}
+	report := &v1alpha1.OpenFGAComplianceReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"},
+		Spec: v1alpha1.OpenFGAComplianceReportSpec{
+			StoreRef: &v1alpha1.StoreReference{Name: "store"},
+			Profile:  "baseline",
+		},
+	}
+	c := newFakeClient(t, store, report)
+
+	got := reconcileComplianceReport(t, c, report)
+
+	if got.Status.Phase != "Completed" {
+		t.Fatalf("expected phase Completed, got %q", got.Status.Phase)
+	}
+	if got.Status.LastScanTime == nil {
+		t.Fatalf("expected LastScanTime to be set")
+	}
+	if len(got.Status.Findings) == 0 {
+		t.Fatalf("expected at least one finding")
+	}
+	if got.Status.PassCount+got.Status.FailCount != int32(len(got.Status.Findings)) {
+		t.Fatalf("expected pass+fail counts to cover every finding, got pass=%d fail=%d findings=%d",
+			got.Status.PassCount, got.Status.FailCount, len(got.Status.Findings))
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionTypeScanCompleted)
+	if cond == nil {
+		t.Fatalf("expected a %s condition", conditionTypeScanCompleted)
+	}
+}
+
+func TestOpenFGAComplianceReportReconcilerFailsWhenTargetMissing(t *testing.T) {
+	report := &v1alpha1.OpenFGAComplianceReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"},
+		Spec: v1alpha1.OpenFGAComplianceReportSpec{
+			StoreRef: &v1alpha1.StoreReference{Name: "missing-store"},
+			Profile:  "baseline",
+		},
+	}
+	c := newFakeClient(t, report)
+
+	got := reconcileComplianceReport(t, c, report)
+
+	if got.Status.Phase != "Failed" {
+		t.Fatalf("expected phase Failed, got %q", got.Status.Phase)
+	}
+}
+
+func TestOpenFGAComplianceReportReconcilerRejectsMissingRef(t *testing.T) {
+	report := &v1alpha1.OpenFGAComplianceReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"},
+		Spec:       v1alpha1.OpenFGAComplianceReportSpec{Profile: "baseline"},
+	}
+	c := newFakeClient(t, report)
+
+	got := reconcileComplianceReport(t, c, report)
+
+	if got.Status.Phase != "Failed" {
+		t.Fatalf("expected phase Failed when neither serverRef nor storeRef is set, got %q", got.Status.Phase)
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/backupengine"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/kms"
+)
+
+const conditionTypeRestoreReady = "Ready"
+
+// OpenFGARestoreReconciler reconciles an OpenFGARestore object by resolving
+// the OpenFGABackup it points at (or the most recent successful one for its
+// StoreRef), reading the snapshot back from its destination, and replaying
+// it into the target store via backupengine.Restore.
+type OpenFGARestoreReconciler struct {
+	client.Client
+	ClientFactory ClientFactory
+	Recorder      record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=openfgarestores,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=openfgarestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=openfgabackups,verbs=get;list
+
+func (r *OpenFGARestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var restore v1alpha1.OpenFGARestore
+	if err := r.Get(ctx, req.NamespacedName, &restore); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if restore.Status.Phase == "Succeeded" || restore.Status.Phase == "Failed" {
+		return ctrl.Result{}, nil
+	}
+
+	now := metav1.Now()
+	restore.Status.Phase = "Running"
+	restore.Status.StartTime = &now
+	if err := r.Status().Update(ctx, &restore); err != nil {
+		return ctrl.Result{}, fmt.Errorf("recording start time: %w", err)
+	}
+
+	if err := r.run(ctx, &restore); err != nil {
+		log.Error(err, "restore failed")
+		restore.Status.Phase = "Failed"
+		completion := metav1.Now()
+		restore.Status.CompletionTime = &completion
+		meta.SetStatusCondition(&restore.Status.Conditions, metav1.Condition{
+			Type:    conditionTypeRestoreReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "RestoreFailed",
+			Message: err.Error(),
+		})
+		if statusErr := r.Status().Update(ctx, &restore); statusErr != nil {
+			return ctrl.Result{}, statusErr
+		}
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *OpenFGARestoreReconciler) run(ctx context.Context, restore *v1alpha1.OpenFGARestore) error {
+	backup, err := r.resolveBackup(ctx, restore)
+	if err != nil {
+		return fmt.Errorf("resolving backup: %w", err)
+	}
+	if backup.Status.Phase != "Succeeded" {
+		return fmt.Errorf("OpenFGABackup %s/%s has not succeeded (phase %q)", backup.Namespace, backup.Name, backup.Status.Phase)
+	}
+
+	var store v1alpha1.OpenFGAStore
+	var storeDefaultDestination *v1alpha1.BackupTargetReference
+	if restore.Spec.StoreRef.Name != "" {
+		if err := r.Get(ctx, client.ObjectKey{Name: restore.Spec.StoreRef.Name, Namespace: restore.Spec.StoreRef.Namespace}, &store); err == nil && store.Spec.Backup != nil {
+			storeDefaultDestination = store.Spec.Backup.DestinationRef
+		}
+	}
+
+	destRef := restore.Spec.DestinationRef
+	if destRef == nil {
+		destRef = backup.Spec.DestinationRef
+	}
+	writer, err := resolveDestination(ctx, r.Client, restore.Namespace, destRef, storeDefaultDestination)
+	if err != nil {
+		return fmt.Errorf("resolving destination: %w", err)
+	}
+
+	key := fmt.Sprintf("%s/%s.snapshot", backup.Namespace, backup.Name)
+
+	manifestData, err := writer.Read(ctx, manifestKey(key))
+	if err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+	var manifest backupengine.Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return fmt.Errorf("decoding manifest: %w", err)
+	}
+
+	payload, err := writer.Read(ctx, key)
+	if err != nil {
+		return fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	cli, storeID, err := resolveStoreClient(ctx, r.Client, r.ClientFactory, restore.Spec.StoreRef)
+	if err != nil {
+		return fmt.Errorf("resolving target store: %w", err)
+	}
+
+	backupReconciler := &OpenFGABackupReconciler{Client: r.Client}
+	provider, err := backupReconciler.buildKMSProvider(ctx, backup.Namespace, backup.Spec.Encryption)
+	if err != nil {
+		return fmt.Errorf("building KMS provider: %w", err)
+	}
+
+	if err := backupengine.Restore(ctx, cli, storeID, backupengine.Snapshot{Manifest: manifest, Payload: payload}, provider); err != nil {
+		var unwrapErr *backupengine.UnwrapError
+		if errors.As(err, &unwrapErr) && r.Recorder != nil {
+			r.Recorder.Event(restore, corev1.EventTypeWarning, kms.EventReasonUnwrapFailed, unwrapErr.Error())
+		}
+		return fmt.Errorf("restoring snapshot: %w", err)
+	}
+
+	completion := metav1.Now()
+	restore.Status.Phase = "Succeeded"
+	restore.Status.CompletionTime = &completion
+	restore.Status.TupleCount = &manifest.TupleCount
+	restore.Status.ModelCount = &manifest.ModelCount
+	restore.Status.Checksum = manifest.Checksum
+	meta.SetStatusCondition(&restore.Status.Conditions, metav1.Condition{
+		Type:   conditionTypeRestoreReady,
+		Status: metav1.ConditionTrue,
+		Reason: "RestoreSucceeded",
+	})
+
+	return r.Status().Update(ctx, restore)
+}
+
+// resolveBackup returns restore.Spec.BackupRef's target, or - when unset -
+// the most recently started successful OpenFGABackup for restore.Spec.StoreRef
+func (r *OpenFGARestoreReconciler) resolveBackup(ctx context.Context, restore *v1alpha1.OpenFGARestore) (*v1alpha1.OpenFGABackup, error) {
+	if restore.Spec.BackupRef != nil {
+		ns := restore.Spec.BackupRef.Namespace
+		if ns == "" {
+			ns = restore.Namespace
+		}
+		var backup v1alpha1.OpenFGABackup
+		if err := r.Get(ctx, client.ObjectKey{Name: restore.Spec.BackupRef.Name, Namespace: ns}, &backup); err != nil {
+			return nil, err
+		}
+		return &backup, nil
+	}
+
+	var backups v1alpha1.OpenFGABackupList
+	if err := r.List(ctx, &backups, client.InNamespace(restore.Namespace)); err != nil {
+		return nil, fmt.Errorf("listing OpenFGABackups: %w", err)
+	}
+
+	var candidates []v1alpha1.OpenFGABackup
+	for _, b := range backups.Items {
+		if b.Status.Phase != "Succeeded" {
+			continue
+		}
+		if b.Spec.StoreRef != restore.Spec.StoreRef {
+			continue
+		}
+		candidates = append(candidates, b)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no successful OpenFGABackup found for storeRef %+v", restore.Spec.StoreRef)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Status.StartTime.After(candidates[j].Status.StartTime.Time)
+	})
+	return &candidates[0], nil
+}
+
+func (r *OpenFGARestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.OpenFGARestore{}).
+		Complete(r)
+}
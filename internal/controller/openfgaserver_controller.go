@@ -0,0 +1,157 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/networkpolicy"
+)
+
+const (
+	conditionTypeNetworkPolicyReady = "NetworkPolicyReady"
+	ciliumNetworkPolicyGroup        = "cilium.io"
+	ciliumNetworkPolicyVersion      = "v2"
+)
+
+// OpenFGAServerReconciler reconciles Spec.NetworkPolicy on an OpenFGAServer
+// into a CiliumNetworkPolicy/CiliumClusterwideNetworkPolicy. This build has
+// no Deployment/Service reconciliation for OpenFGAServer yet, so it is
+// deliberately narrow: it only manages the network policy, and only for
+// Engine "cilium", gated on the CRD actually being registered in the
+// cluster - as NetworkPolicyConfig.Engine's own doc comment already
+// promises. Engine "kubernetes" (the field's default) is not implemented by
+// this build; Reconcile fails the resource rather than silently doing
+// nothing for it.
+type OpenFGAServerReconciler struct {
+	client.Client
+}
+
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=openfgaservers,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=openfgaservers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cilium.io,resources=ciliumnetworkpolicies;ciliumclusterwidenetworkpolicies,verbs=get;list;watch;create;update;patch;delete
+
+func (r *OpenFGAServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var server v1alpha1.OpenFGAServer
+	if err := r.Get(ctx, req.NamespacedName, &server); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	cfg := server.Spec.NetworkPolicy
+	if cfg == nil || cfg.Enabled == nil || !*cfg.Enabled {
+		return ctrl.Result{}, nil
+	}
+
+	if cfg.Engine != "cilium" {
+		return r.failNetworkPolicy(ctx, &server, fmt.Errorf("networkPolicy.engine %q is not implemented by this operator build", cfg.Engine))
+	}
+
+	if err := r.reconcileCiliumPolicy(ctx, &server, cfg); err != nil {
+		log.Error(err, "reconciling CiliumNetworkPolicy")
+		return r.failNetworkPolicy(ctx, &server, err)
+	}
+
+	meta.SetStatusCondition(&server.Status.Conditions, metav1.Condition{
+		Type:    conditionTypeNetworkPolicyReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Applied",
+		Message: "CiliumNetworkPolicy applied",
+	})
+	return ctrl.Result{}, r.Status().Update(ctx, &server)
+}
+
+func (r *OpenFGAServerReconciler) failNetworkPolicy(ctx context.Context, server *v1alpha1.OpenFGAServer, cause error) (ctrl.Result, error) {
+	meta.SetStatusCondition(&server.Status.Conditions, metav1.Condition{
+		Type:    conditionTypeNetworkPolicyReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Failed",
+		Message: cause.Error(),
+	})
+	return ctrl.Result{}, r.Status().Update(ctx, server)
+}
+
+// reconcileCiliumPolicy creates or updates the CiliumNetworkPolicy for
+// server, after confirming the CRD is actually registered - a Cilium CNI may
+// not be installed in every cluster this operator targets
+func (r *OpenFGAServerReconciler) reconcileCiliumPolicy(ctx context.Context, server *v1alpha1.OpenFGAServer, cfg *v1alpha1.NetworkPolicyConfig) error {
+	gk := schema.GroupKind{Group: ciliumNetworkPolicyGroup, Kind: "CiliumNetworkPolicy"}
+	if cfg.Scope == "cluster" {
+		gk.Kind = "CiliumClusterwideNetworkPolicy"
+	}
+	if _, err := r.RESTMapper().RESTMapping(gk, ciliumNetworkPolicyVersion); err != nil {
+		if meta.IsNoMatchError(err) {
+			return fmt.Errorf("networkPolicy.engine is cilium but the %s CRD is not registered in this cluster", gk.Kind)
+		}
+		return fmt.Errorf("checking for the %s CRD: %w", gk.Kind, err)
+	}
+
+	isController := true
+	owner := metav1.OwnerReference{
+		APIVersion: v1alpha1.GroupVersion.String(),
+		Kind:       "OpenFGAServer",
+		Name:       server.Name,
+		UID:        server.UID,
+		Controller: &isController,
+	}
+
+	policy := networkpolicy.CiliumNetworkPolicy(server.Name, server.Namespace, cfg, selectorLabelsForServer(server), owner)
+
+	var existing unstructured.Unstructured
+	existing.SetGroupVersionKind(policy.GroupVersionKind())
+	key := client.ObjectKeyFromObject(policy)
+
+	switch err := r.Get(ctx, key, &existing); {
+	case apierrors.IsNotFound(err):
+		return r.Create(ctx, policy)
+	case err != nil:
+		return fmt.Errorf("getting existing %s %s: %w", policy.GetKind(), key, err)
+	default:
+		policy.SetResourceVersion(existing.GetResourceVersion())
+		return r.Update(ctx, policy)
+	}
+}
+
+// selectorLabelsForServer returns the pod labels this build expects an
+// OpenFGAServer's eventual Deployment to carry, so the generated network
+// policy's endpointSelector scopes to the right endpoints once that
+// Deployment exists. No controller in this build creates that Deployment
+// yet, so this is a forward-declared convention, not an observed selector.
+func selectorLabelsForServer(server *v1alpha1.OpenFGAServer) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":     "openfga",
+		"app.kubernetes.io/instance": server.Name,
+	}
+}
+
+func (r *OpenFGAServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.OpenFGAServer{}).
+		Complete(r)
+}
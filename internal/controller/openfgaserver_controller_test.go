@@ -0,0 +1,140 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+// newFakeClientWithCiliumCRD builds a fake client whose RESTMapper optionally
+// knows about the CiliumNetworkPolicy/CiliumClusterwideNetworkPolicy CRDs,
+// letting tests exercise both sides of the CRD-discovery gate.
+func newFakeClientWithCiliumCRD(t *testing.T, registerCiliumCRD bool, objs ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme returned error: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme returned error: %v", err)
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	if registerCiliumCRD {
+		gv := schema.GroupVersion{Group: ciliumNetworkPolicyGroup, Version: ciliumNetworkPolicyVersion}
+		mapper.Add(gv.WithKind("CiliumNetworkPolicy"), meta.RESTScopeNamespace)
+		mapper.Add(gv.WithKind("CiliumClusterwideNetworkPolicy"), meta.RESTScopeRoot)
+	}
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).
+		WithObjects(objs...).
+		WithStatusSubresource(&v1alpha1.OpenFGAServer{}).
+		Build()
+}
+
+func serverWithNetworkPolicy(name, engine string, enabled bool) *v1alpha1.OpenFGAServer {
+	return &v1alpha1.OpenFGAServer{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1alpha1.OpenFGAServerSpec{
+			Image:    "openfga/openfga:v1.5.0",
+			Database: v1alpha1.DatabaseConfig{Type: "postgres"},
+			NetworkPolicy: &v1alpha1.NetworkPolicyConfig{
+				Enabled: &enabled,
+				Engine:  engine,
+			},
+		},
+	}
+}
+
+func reconcileServer(t *testing.T, c client.Client, server *v1alpha1.OpenFGAServer) v1alpha1.OpenFGAServer {
+	t.Helper()
+	r := &OpenFGAServerReconciler{Client: c}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(server)}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	var got v1alpha1.OpenFGAServer
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(server), &got); err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	return got
+}
+
+func TestOpenFGAServerReconcilerSkipsWhenNetworkPolicyDisabled(t *testing.T) {
+	server := serverWithNetworkPolicy("server", "cilium", false)
+	c := newFakeClientWithCiliumCRD(t, true, server)
+
+	got := reconcileServer(t, c, server)
+
+	if cond := meta.FindStatusCondition(got.Status.Conditions, conditionTypeNetworkPolicyReady); cond != nil {
+		t.Fatalf("expected no %s condition when disabled, got %+v", conditionTypeNetworkPolicyReady, cond)
+	}
+}
+
+func TestOpenFGAServerReconcilerFailsForUnimplementedEngine(t *testing.T) {
+	server := serverWithNetworkPolicy("server", "kubernetes", true)
+	c := newFakeClientWithCiliumCRD(t, true, server)
+
+	got := reconcileServer(t, c, server)
+
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionTypeNetworkPolicyReady)
+	if cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Fatalf("expected a False %s condition for an unimplemented engine, got %+v", conditionTypeNetworkPolicyReady, cond)
+	}
+}
+
+func TestOpenFGAServerReconcilerFailsWhenCiliumCRDNotRegistered(t *testing.T) {
+	server := serverWithNetworkPolicy("server", "cilium", true)
+	c := newFakeClientWithCiliumCRD(t, false, server)
+
+	got := reconcileServer(t, c, server)
+
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionTypeNetworkPolicyReady)
+	if cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Fatalf("expected a False %s condition when the CRD is absent, got %+v", conditionTypeNetworkPolicyReady, cond)
+	}
+}
+
+func TestOpenFGAServerReconcilerCreatesCiliumNetworkPolicy(t *testing.T) {
+	server := serverWithNetworkPolicy("server", "cilium", true)
+	c := newFakeClientWithCiliumCRD(t, true, server)
+
+	got := reconcileServer(t, c, server)
+
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionTypeNetworkPolicyReady)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Fatalf("expected a True %s condition, got %+v", conditionTypeNetworkPolicyReady, cond)
+	}
+
+	var policy unstructured.Unstructured
+	policy.SetGroupVersionKind(schema.GroupVersionKind{Group: ciliumNetworkPolicyGroup, Version: ciliumNetworkPolicyVersion, Kind: "CiliumNetworkPolicy"})
+	if err := c.Get(context.Background(), client.ObjectKey{Name: "server", Namespace: "default"}, &policy); err != nil {
+		t.Fatalf("expected the CiliumNetworkPolicy to be created, Get returned error: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+const conditionTypeConnectionReady = "Ready"
+
+// OpenFGAServerConnectionReconciler reconciles an OpenFGAServerConnection by
+// resolving its CurrentContext exactly as clientForConnectionRef does, so a
+// misconfigured context (unknown cluster/user, missing secret, bad OIDC
+// credentials) surfaces as a status condition instead of only failing later
+// inside an OpenFGAStore reconcile. It also watches every Secret in the
+// cluster, re-resolving every connection on any Secret change, so a
+// credential rotation re-triggers validation.
+type OpenFGAServerConnectionReconciler struct {
+	client.Client
+	ClientFactory ClientFactory
+}
+
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=openfgaserverconnections,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=openfgaserverconnections/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+func (r *OpenFGAServerConnectionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var conn v1alpha1.OpenFGAServerConnection
+	if err := r.Get(ctx, req.NamespacedName, &conn); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	now := metav1.Now()
+	conn.Status.LastReconcileTime = &now
+
+	ref := v1alpha1.OpenFGAServerConnectionReference{Name: conn.Name, Namespace: conn.Namespace}
+	if _, err := clientForConnectionRef(ctx, r.Client, r.ClientFactory, conn.Namespace, ref); err != nil {
+		log.Error(err, "resolving OpenFGAServerConnection context")
+		conn.Status.Phase = "Failed"
+		meta.SetStatusCondition(&conn.Status.Conditions, metav1.Condition{
+			Type:    conditionTypeConnectionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ContextResolutionFailed",
+			Message: err.Error(),
+		})
+		return ctrl.Result{}, r.Status().Update(ctx, &conn)
+	}
+
+	for _, c := range conn.Spec.Clusters {
+		if c.Name == contextCluster(conn, conn.Spec.CurrentContext) {
+			conn.Status.ResolvedServer = c.Server
+		}
+	}
+
+	conn.Status.Phase = "Ready"
+	meta.SetStatusCondition(&conn.Status.Conditions, metav1.Condition{
+		Type:   conditionTypeConnectionReady,
+		Status: metav1.ConditionTrue,
+		Reason: "ContextResolved",
+	})
+
+	if hasOIDCUser(conn) {
+		conn.Status.LastTokenRefresh = &now
+	}
+
+	return ctrl.Result{}, r.Status().Update(ctx, &conn)
+}
+
+func contextCluster(conn v1alpha1.OpenFGAServerConnection, name string) string {
+	for _, c := range conn.Spec.Contexts {
+		if c.Name == name {
+			return c.Cluster
+		}
+	}
+	return ""
+}
+
+func hasOIDCUser(conn v1alpha1.OpenFGAServerConnection) bool {
+	var userName string
+	for _, c := range conn.Spec.Contexts {
+		if c.Name == conn.Spec.CurrentContext {
+			userName = c.User
+		}
+	}
+	for _, u := range conn.Spec.Users {
+		if u.Name == userName && u.OIDC != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *OpenFGAServerConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.OpenFGAServerConnection{}).
+		// Any Secret change in the cluster may be a credential this
+		// connection's CurrentContext depends on; re-resolving every
+		// OpenFGAServerConnection on every Secret write is wasteful at scale
+		// but correct, and connections are expected to be few.
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.mapSecretToConnections)).
+		Complete(r)
+}
+
+func (r *OpenFGAServerConnectionReconciler) mapSecretToConnections(ctx context.Context, _ client.Object) []ctrl.Request {
+	var connections v1alpha1.OpenFGAServerConnectionList
+	if err := r.List(ctx, &connections); err != nil {
+		return nil
+	}
+	requests := make([]ctrl.Request, 0, len(connections.Items))
+	for _, c := range connections.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&c)})
+	}
+	return requests
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/kms"
+)
+
+// rotationCheckInterval bounds how often an OpenFGAStore with a
+// RotationPolicy is re-reconciled purely to notice that MaxKeyAge has
+// elapsed, since nothing else about the store changes at that moment.
+const rotationCheckInterval = time.Hour
+
+// OpenFGAStoreReconciler reconciles an OpenFGAStore object. Today it only
+// tracks backup encryption key rotation: it asks the configured kms.Provider
+// for its active key version and, once MaxKeyAge has elapsed since the last
+// rotation, records the new version and timestamp in status.
+type OpenFGAStoreReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=openfgastores,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=openfga.authcore.io,resources=openfgastores/status,verbs=get;update;patch
+
+func (r *OpenFGAStoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var store v1alpha1.OpenFGAStore
+	if err := r.Get(ctx, req.NamespacedName, &store); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if store.Spec.Backup == nil || store.Spec.Backup.Encryption == nil || store.Spec.Backup.Encryption.RotationPolicy == nil {
+		return ctrl.Result{}, nil
+	}
+	policy := store.Spec.Backup.Encryption.RotationPolicy
+
+	provider, err := (&OpenFGABackupReconciler{Client: r.Client}).buildKMSProvider(ctx, store.Namespace, store.Spec.Backup.Encryption)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if provider == nil {
+		return ctrl.Result{}, nil
+	}
+
+	version, err := provider.KeyVersion(ctx)
+	if err != nil {
+		if r.Recorder != nil {
+			r.Recorder.Event(&store, corev1.EventTypeWarning, kms.EventReasonProviderUnavailable, err.Error())
+		}
+		log.Error(err, "checking KMS provider key version")
+		return ctrl.Result{RequeueAfter: rotationCheckInterval}, nil
+	}
+
+	// RotateOnSchedule re-wraps existing backups' data encryption keys under
+	// the provider's current key on a cron schedule even if MaxKeyAge hasn't
+	// elapsed. Re-wrapping already-written snapshots requires walking their
+	// destinations, which this operator build doesn't implement yet - the
+	// status below only reflects MaxKeyAge-driven rotation of new backups.
+
+	due := store.Status.LastKeyRotation == nil
+	if !due && policy.MaxKeyAge != nil {
+		due = time.Since(store.Status.LastKeyRotation.Time) >= policy.MaxKeyAge.Duration
+	}
+	if due && store.Status.ActiveKeyVersion != version {
+		now := metav1.Now()
+		store.Status.ActiveKeyVersion = version
+		store.Status.LastKeyRotation = &now
+		if err := r.Status().Update(ctx, &store); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: rotationCheckInterval}, nil
+}
+
+func (r *OpenFGAStoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.OpenFGAStore{}).
+		Complete(r)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme returned error: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme returned error: %v", err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithStatusSubresource(&v1alpha1.OpenFGAStore{}, &v1alpha1.AuthorizationModel{}, &v1alpha1.AuthorizationQuery{}, &v1alpha1.OpenFGAComplianceReport{}).
+		Build()
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+func storeWithRotation(name string) *v1alpha1.OpenFGAStore {
+	return &v1alpha1.OpenFGAStore{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1alpha1.OpenFGAStoreSpec{
+			ServerRef: v1alpha1.ServerReference{Name: "server"},
+			Backup: &v1alpha1.BackupConfig{
+				Enabled: boolPtr(true),
+				Encryption: &v1alpha1.EncryptionConfig{
+					Enabled:  boolPtr(true),
+					Provider: "secret",
+					KeySecret: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "encryption-key"},
+						Key:                  "key",
+					},
+					RotationPolicy: &v1alpha1.RotationPolicy{},
+				},
+			},
+		},
+	}
+}
+
+func keySecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "encryption-key", Namespace: "default"},
+		Data:       map[string][]byte{"key": make([]byte, 32)},
+	}
+}
+
+func TestOpenFGAStoreReconcilerSkipsStoresWithoutRotationPolicy(t *testing.T) {
+	store := &v1alpha1.OpenFGAStore{
+		ObjectMeta: metav1.ObjectMeta{Name: "store", Namespace: "default"},
+	}
+	c := newFakeClient(t, store)
+	r := &OpenFGAStoreReconciler{Client: c}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(store)}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+
+	var got v1alpha1.OpenFGAStore
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(store), &got); err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got.Status.ActiveKeyVersion != "" {
+		t.Fatalf("expected ActiveKeyVersion to stay empty, got %q", got.Status.ActiveKeyVersion)
+	}
+}
+
+func TestOpenFGAStoreReconcilerRecordsInitialRotation(t *testing.T) {
+	store := storeWithRotation("store")
+	c := newFakeClient(t, store, keySecret())
+	r := &OpenFGAStoreReconciler{Client: c}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(store)}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+
+	var got v1alpha1.OpenFGAStore
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(store), &got); err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got.Status.ActiveKeyVersion == "" {
+		t.Fatalf("expected ActiveKeyVersion to be recorded")
+	}
+	if got.Status.LastKeyRotation == nil {
+		t.Fatalf("expected LastKeyRotation to be recorded")
+	}
+}
+
+func TestOpenFGAStoreReconcilerDoesNotRotateBeforeMaxKeyAge(t *testing.T) {
+	store := storeWithRotation("store")
+	store.Spec.Backup.Encryption.RotationPolicy.MaxKeyAge = &metav1.Duration{Duration: 24 * 3600 * 1e9}
+	now := metav1.Now()
+	store.Status.LastKeyRotation = &now
+	store.Status.ActiveKeyVersion = "stale-version"
+	c := newFakeClient(t, store, keySecret())
+	r := &OpenFGAStoreReconciler{Client: c}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(store)}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+
+	var got v1alpha1.OpenFGAStore
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(store), &got); err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got.Status.ActiveKeyVersion != "stale-version" {
+		t.Fatalf("expected ActiveKeyVersion to remain unchanged before MaxKeyAge elapses, got %q", got.Status.ActiveKeyVersion)
+	}
+}
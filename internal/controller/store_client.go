@@ -0,0 +1,218 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller implements the reconcilers behind this operator's CRDs.
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/oauth2/clientcredentials"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/openfga"
+)
+
+// ClientFactory builds an openfga.Client for a single resolved endpoint.
+// Reconcilers depend on this instead of a concrete HTTP/gRPC implementation
+// so they can be unit tested with a fake factory.
+type ClientFactory func(ctx context.Context, endpoint string, opts ClientOptions) (openfga.Client, error)
+
+// ClientOptions carries the authentication material resolved for a
+// ClientFactory call: at most one of BearerToken/ClientCert is populated,
+// selected by whichever OpenFGAConnectionUser field was set on the resolved
+// context, or neither for the default in-cluster OpenFGAServer path
+type ClientOptions struct {
+	CACert                []byte
+	InsecureSkipTLSVerify bool
+
+	BearerToken string
+
+	ClientCert []byte
+	ClientKey  []byte
+}
+
+// resolveStoreClient builds an openfga.Client and resolves the OpenFGA
+// store ID for ref. When ref.StoreID is set directly alongside ref.ServerRef,
+// the in-cluster OpenFGAServer path is used without looking up an
+// OpenFGAStore object. Otherwise ref.Name/ref.Namespace identify an
+// OpenFGAStore resource, and its Spec.ConnectionRef (if set) takes precedence
+// over Spec.ServerRef, mirroring OpenFGAStoreSpec's own precedence rule.
+func resolveStoreClient(ctx context.Context, c client.Client, factory ClientFactory, ref v1alpha1.StoreReference) (openfga.Client, string, error) {
+	if ref.StoreID != "" && ref.ServerRef.Name != "" {
+		cli, err := clientForServerRef(ctx, c, factory, ref.Namespace, ref.ServerRef)
+		return cli, ref.StoreID, err
+	}
+
+	if ref.Name == "" {
+		return nil, "", fmt.Errorf("storeRef must set either (name, namespace) or (storeID, serverRef)")
+	}
+
+	var store v1alpha1.OpenFGAStore
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}, &store); err != nil {
+		return nil, "", fmt.Errorf("resolving OpenFGAStore %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+
+	if store.Spec.ConnectionRef != nil {
+		cli, err := clientForConnectionRef(ctx, c, factory, store.Namespace, *store.Spec.ConnectionRef)
+		return cli, store.Status.StoreID, err
+	}
+
+	cli, err := clientForServerRef(ctx, c, factory, store.Namespace, store.Spec.ServerRef)
+	return cli, store.Status.StoreID, err
+}
+
+// clientForConnectionRef resolves ref's OpenFGAServerConnection, follows
+// Spec.CurrentContext to a (cluster, user) pair, and builds an openfga.Client
+// against that cluster's Server using that user's credentials.
+func clientForConnectionRef(ctx context.Context, c client.Client, factory ClientFactory, namespace string, ref v1alpha1.OpenFGAServerConnectionReference) (openfga.Client, error) {
+	ns := ref.Namespace
+	if ns == "" {
+		ns = namespace
+	}
+
+	var conn v1alpha1.OpenFGAServerConnection
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: ns}, &conn); err != nil {
+		return nil, fmt.Errorf("resolving OpenFGAServerConnection %s/%s: %w", ns, ref.Name, err)
+	}
+
+	var ctxRef *v1alpha1.OpenFGAConnectionContext
+	for i := range conn.Spec.Contexts {
+		if conn.Spec.Contexts[i].Name == conn.Spec.CurrentContext {
+			ctxRef = &conn.Spec.Contexts[i]
+			break
+		}
+	}
+	if ctxRef == nil {
+		return nil, fmt.Errorf("OpenFGAServerConnection %s/%s has no context named %q", ns, ref.Name, conn.Spec.CurrentContext)
+	}
+
+	var cluster *v1alpha1.OpenFGAConnectionCluster
+	for i := range conn.Spec.Clusters {
+		if conn.Spec.Clusters[i].Name == ctxRef.Cluster {
+			cluster = &conn.Spec.Clusters[i]
+			break
+		}
+	}
+	if cluster == nil {
+		return nil, fmt.Errorf("OpenFGAServerConnection %s/%s context %q references unknown cluster %q", ns, ref.Name, ctxRef.Name, ctxRef.Cluster)
+	}
+
+	var user *v1alpha1.OpenFGAConnectionUser
+	for i := range conn.Spec.Users {
+		if conn.Spec.Users[i].Name == ctxRef.User {
+			user = &conn.Spec.Users[i]
+			break
+		}
+	}
+	if user == nil {
+		return nil, fmt.Errorf("OpenFGAServerConnection %s/%s context %q references unknown user %q", ns, ref.Name, ctxRef.Name, ctxRef.User)
+	}
+
+	opts := ClientOptions{
+		InsecureSkipTLSVerify: cluster.InsecureSkipTLSVerify != nil && *cluster.InsecureSkipTLSVerify,
+	}
+
+	if cluster.CABundleSecret != nil {
+		ca, err := readSecretKeySelector(ctx, c, ns, cluster.CABundleSecret)
+		if err != nil {
+			return nil, fmt.Errorf("resolving CA bundle: %w", err)
+		}
+		opts.CACert = ca
+	}
+
+	switch {
+	case user.BearerTokenSecret != nil:
+		token, err := readSecretKeySelector(ctx, c, ns, user.BearerTokenSecret)
+		if err != nil {
+			return nil, fmt.Errorf("resolving bearer token: %w", err)
+		}
+		opts.BearerToken = string(token)
+
+	case user.ClientCertSecret != nil:
+		certNS := user.ClientCertSecret.Namespace
+		if certNS == "" {
+			certNS = ns
+		}
+		var secret corev1.Secret
+		if err := c.Get(ctx, client.ObjectKey{Name: user.ClientCertSecret.Name, Namespace: certNS}, &secret); err != nil {
+			return nil, fmt.Errorf("resolving client cert secret %s/%s: %w", certNS, user.ClientCertSecret.Name, err)
+		}
+		opts.ClientCert = secret.Data[corev1.TLSCertKey]
+		opts.ClientKey = secret.Data[corev1.TLSPrivateKeyKey]
+
+	case user.OIDC != nil:
+		secret, err := readSecretKeySelector(ctx, c, ns, user.OIDC.ClientSecretSecret)
+		if err != nil {
+			return nil, fmt.Errorf("resolving OIDC client secret: %w", err)
+		}
+		cfg := clientcredentials.Config{
+			ClientID:     user.OIDC.ClientID,
+			ClientSecret: string(secret),
+			TokenURL:     user.OIDC.IssuerURL + "/oauth/token",
+			Scopes:       user.OIDC.Scopes,
+		}
+		// TokenSource refreshes automatically on every Token() call once the
+		// previously issued token is within its expiry window, so fetching
+		// here rather than caching gives each reconcile a valid token without
+		// the controller managing token lifetime itself.
+		token, err := cfg.TokenSource(ctx).Token()
+		if err != nil {
+			return nil, fmt.Errorf("fetching OIDC token: %w", err)
+		}
+		opts.BearerToken = token.AccessToken
+
+	case user.AWSSigV4 != nil:
+		return nil, fmt.Errorf("user %q uses awsSigV4 authentication, which this operator build does not yet support", user.Name)
+	}
+
+	return factory(ctx, cluster.Server, opts)
+}
+
+func readSecretKeySelector(ctx context.Context, c client.Client, namespace string, ref *corev1.SecretKeySelector) ([]byte, error) {
+	var secret corev1.Secret
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, &secret); err != nil {
+		return nil, fmt.Errorf("resolving secret %s/%s: %w", namespace, ref.Name, err)
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no key %q", namespace, ref.Name, ref.Key)
+	}
+	return value, nil
+}
+
+func clientForServerRef(ctx context.Context, c client.Client, factory ClientFactory, namespace string, ref v1alpha1.ServerReference) (openfga.Client, error) {
+	if ref.Endpoint != "" {
+		return factory(ctx, ref.Endpoint, ClientOptions{})
+	}
+
+	ns := ref.Namespace
+	if ns == "" {
+		ns = namespace
+	}
+
+	var server v1alpha1.OpenFGAServer
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: ns}, &server); err != nil {
+		return nil, fmt.Errorf("resolving OpenFGAServer %s/%s: %w", ns, ref.Name, err)
+	}
+	if server.Status.ServiceURL == "" {
+		return nil, fmt.Errorf("OpenFGAServer %s/%s has no status.serviceURL yet", ns, ref.Name)
+	}
+	return factory(ctx, server.Status.ServiceURL, ClientOptions{})
+}
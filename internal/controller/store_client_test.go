@@ -0,0 +1,111 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+	"github.com/jralmaraz/authcore-openfga-operator/internal/openfga"
+)
+
+func recordingFactory(t *testing.T) (ClientFactory, func() (string, ClientOptions)) {
+	t.Helper()
+	var gotEndpoint string
+	var gotOpts ClientOptions
+	return func(ctx context.Context, endpoint string, opts ClientOptions) (openfga.Client, error) {
+		gotEndpoint = endpoint
+		gotOpts = opts
+		return nil, nil
+	}, func() (string, ClientOptions) { return gotEndpoint, gotOpts }
+}
+
+func connectionWithBearerToken(name string) *v1alpha1.OpenFGAServerConnection {
+	return &v1alpha1.OpenFGAServerConnection{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1alpha1.OpenFGAServerConnectionSpec{
+			Clusters: []v1alpha1.OpenFGAConnectionCluster{
+				{Name: "prod", Server: "https://fga.example.com"},
+			},
+			Users: []v1alpha1.OpenFGAConnectionUser{
+				{
+					Name: "ci",
+					BearerTokenSecret: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "fga-token"},
+						Key:                  "token",
+					},
+				},
+			},
+			Contexts: []v1alpha1.OpenFGAConnectionContext{
+				{Name: "prod-ci", Cluster: "prod", User: "ci"},
+			},
+			CurrentContext: "prod-ci",
+		},
+	}
+}
+
+func TestClientForConnectionRefResolvesBearerToken(t *testing.T) {
+	conn := connectionWithBearerToken("conn")
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "fga-token", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("s3cr3t")},
+	}
+	c := newFakeClient(t, conn, secret)
+	factory, captured := recordingFactory(t)
+
+	ref := v1alpha1.OpenFGAServerConnectionReference{Name: "conn", Namespace: "default"}
+	if _, err := clientForConnectionRef(context.Background(), c, factory, "default", ref); err != nil {
+		t.Fatalf("clientForConnectionRef returned error: %v", err)
+	}
+
+	endpoint, opts := captured()
+	if endpoint != "https://fga.example.com" {
+		t.Fatalf("expected endpoint https://fga.example.com, got %q", endpoint)
+	}
+	if opts.BearerToken != "s3cr3t" {
+		t.Fatalf("expected bearer token s3cr3t, got %q", opts.BearerToken)
+	}
+}
+
+func TestClientForConnectionRefRejectsUnknownCurrentContext(t *testing.T) {
+	conn := connectionWithBearerToken("conn")
+	conn.Spec.CurrentContext = "missing"
+	c := newFakeClient(t, conn)
+	factory, _ := recordingFactory(t)
+
+	ref := v1alpha1.OpenFGAServerConnectionReference{Name: "conn", Namespace: "default"}
+	if _, err := clientForConnectionRef(context.Background(), c, factory, "default", ref); err == nil {
+		t.Fatalf("expected an error for an unknown currentContext")
+	}
+}
+
+func TestClientForConnectionRefRejectsAWSSigV4(t *testing.T) {
+	conn := connectionWithBearerToken("conn")
+	conn.Spec.Users[0].BearerTokenSecret = nil
+	conn.Spec.Users[0].AWSSigV4 = &v1alpha1.AWSSigV4Auth{Region: "us-east-1"}
+	c := newFakeClient(t, conn)
+	factory, _ := recordingFactory(t)
+
+	ref := v1alpha1.OpenFGAServerConnectionReference{Name: "conn", Namespace: "default"}
+	if _, err := clientForConnectionRef(context.Background(), c, factory, "default", ref); err == nil {
+		t.Fatalf("expected an explicit not-yet-supported error for awsSigV4")
+	}
+}
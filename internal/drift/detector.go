@@ -0,0 +1,131 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package drift compares the userset tree shape OpenFGA's Expand API
+// returns for each type#relation pair against the shape implied by an
+// AuthorizationModel's local Relation definitions. It ignores tuple set
+// contents entirely: drift here means someone wrote a different
+// AuthorizationModel out-of-band to the same store, or the controller failed
+// to push a CRD edit, not that tuples changed.
+package drift
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+// Node types mirror the shapes OpenFGA's Expand response can return for a
+// userset tree, plus two sentinel values Detect uses for pairs present on
+// only one side of the comparison.
+const (
+	NodeTypeLeaf           = "Leaf"
+	NodeTypeUnion          = "Union"
+	NodeTypeIntersection   = "Intersection"
+	NodeTypeDifference     = "Difference"
+	NodeTypeTupleToUserset = "TupleToUserset"
+
+	// NodeTypeMissing marks a type#relation defined locally but absent from observed
+	NodeTypeMissing = "Missing"
+
+	// NodeTypeUndefined marks a type#relation present in observed but not defined locally
+	NodeTypeUndefined = "Undefined"
+
+	// NodeTypeExpandFailed marks a type#relation whose live Expand call
+	// itself errored, so its true shape could not be determined. Detect
+	// reports it as drifted rather than silently skipping the pair, since an
+	// Expand failure (a lapsed credential, a deleted store) is itself
+	// something status.driftReport should surface, not swallow.
+	NodeTypeExpandFailed = "ExpandFailed"
+)
+
+// ExpectedNodeType derives the userset tree node type implied by a locally
+// defined Relation, the same shape OpenFGA's Expand call would return for it.
+func ExpectedNodeType(rel v1alpha1.Relation) string {
+	switch {
+	case rel.Union != nil:
+		return NodeTypeUnion
+	case rel.Intersection != nil:
+		return NodeTypeIntersection
+	case rel.Difference != nil:
+		return NodeTypeDifference
+	case rel.TupleToUserset != nil:
+		return NodeTypeTupleToUserset
+	default:
+		// This (direct reference) and ComputedUserset both expand to a leaf node
+		return NodeTypeLeaf
+	}
+}
+
+// Detect compares every type#relation pair defined in schema, and every
+// type#relation pair present in observed, so drift is caught whether a
+// relation's shape changed, a relation was deleted from the live store, or a
+// relation was added to the live store out-of-band. observed is keyed by
+// "type#relation" and is populated by the caller issuing one Expand call per
+// pair against the live store. Mismatches are returned sorted by key so
+// repeated calls over the same inputs are stable for callers that diff
+// against a previously stored DriftReport.
+func Detect(schema v1alpha1.AuthorizationSchema, observed map[string]string) v1alpha1.DriftReport {
+	expected := map[string]string{}
+	for _, td := range schema.TypeDefinitions {
+		for relName, rel := range td.Relations {
+			expected[td.Type+"#"+relName] = ExpectedNodeType(rel)
+		}
+	}
+
+	keys := make(map[string]struct{}, len(expected)+len(observed))
+	for k := range expected {
+		keys[k] = struct{}{}
+	}
+	for k := range observed {
+		keys[k] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	report := v1alpha1.DriftReport{}
+	for _, key := range sorted {
+		expectedType, haveExpected := expected[key]
+		if !haveExpected {
+			expectedType = NodeTypeUndefined
+		}
+
+		observedType, haveObserved := observed[key]
+		if !haveObserved {
+			observedType = NodeTypeMissing
+		}
+
+		if expectedType == observedType {
+			continue
+		}
+
+		typ, relName, _ := strings.Cut(key, "#")
+		report.Drifted = true
+		report.Mismatches = append(report.Mismatches, v1alpha1.RelationDrift{
+			Type:             typ,
+			Relation:         relName,
+			ExpectedNodeType: expectedType,
+			ObservedNodeType: observedType,
+		})
+	}
+
+	return report
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift
+
+import (
+	"testing"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+func schemaWithRelations(relations map[string]v1alpha1.Relation) v1alpha1.AuthorizationSchema {
+	return v1alpha1.AuthorizationSchema{
+		TypeDefinitions: []v1alpha1.TypeDefinition{
+			{Type: "user"},
+			{Type: "document", Relations: relations},
+		},
+	}
+}
+
+func TestExpectedNodeType(t *testing.T) {
+	cases := []struct {
+		name string
+		rel  v1alpha1.Relation
+		want string
+	}{
+		{"direct", v1alpha1.Relation{This: &v1alpha1.RelationReference{Type: "user"}}, NodeTypeLeaf},
+		{"computedUserset", v1alpha1.Relation{ComputedUserset: &v1alpha1.ComputedUserset{Relation: "owner"}}, NodeTypeLeaf},
+		{"union", v1alpha1.Relation{Union: &v1alpha1.Union{Children: []v1alpha1.Relation{{}, {}}}}, NodeTypeUnion},
+		{"intersection", v1alpha1.Relation{Intersection: &v1alpha1.Intersection{Children: []v1alpha1.Relation{{}, {}}}}, NodeTypeIntersection},
+		{"difference", v1alpha1.Relation{Difference: &v1alpha1.Difference{}}, NodeTypeDifference},
+		{"tupleToUserset", v1alpha1.Relation{TupleToUserset: &v1alpha1.TupleToUserset{}}, NodeTypeTupleToUserset},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ExpectedNodeType(tc.rel); got != tc.want {
+				t.Fatalf("ExpectedNodeType() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDetectNoDriftWhenShapesMatch(t *testing.T) {
+	schema := schemaWithRelations(map[string]v1alpha1.Relation{
+		"viewer": {This: &v1alpha1.RelationReference{Type: "user"}},
+	})
+	observed := map[string]string{"document#viewer": NodeTypeLeaf}
+
+	report := Detect(schema, observed)
+
+	if report.Drifted {
+		t.Fatalf("expected no drift, got %+v", report.Mismatches)
+	}
+	if len(report.Mismatches) != 0 {
+		t.Fatalf("expected no mismatches, got %v", report.Mismatches)
+	}
+}
+
+func TestDetectFlagsShapeMismatch(t *testing.T) {
+	schema := schemaWithRelations(map[string]v1alpha1.Relation{
+		"viewer": {Union: &v1alpha1.Union{Children: []v1alpha1.Relation{{}, {}}}},
+	})
+	observed := map[string]string{"document#viewer": NodeTypeLeaf}
+
+	report := Detect(schema, observed)
+
+	if !report.Drifted {
+		t.Fatalf("expected drift to be detected")
+	}
+	if len(report.Mismatches) != 1 {
+		t.Fatalf("expected exactly one mismatch, got %d", len(report.Mismatches))
+	}
+	m := report.Mismatches[0]
+	if m.Type != "document" || m.Relation != "viewer" || m.ExpectedNodeType != NodeTypeUnion || m.ObservedNodeType != NodeTypeLeaf {
+		t.Fatalf("unexpected mismatch: %+v", m)
+	}
+}
+
+func TestDetectFlagsRelationMissingFromObserved(t *testing.T) {
+	schema := schemaWithRelations(map[string]v1alpha1.Relation{
+		"viewer": {This: &v1alpha1.RelationReference{Type: "user"}},
+	})
+
+	report := Detect(schema, map[string]string{})
+
+	if !report.Drifted {
+		t.Fatalf("expected drift to be detected")
+	}
+	if report.Mismatches[0].ObservedNodeType != NodeTypeMissing {
+		t.Fatalf("expected ObservedNodeType=Missing, got %+v", report.Mismatches[0])
+	}
+}
+
+func TestDetectFlagsRelationUndefinedLocally(t *testing.T) {
+	schema := schemaWithRelations(nil)
+	observed := map[string]string{"document#viewer": NodeTypeLeaf}
+
+	report := Detect(schema, observed)
+
+	if !report.Drifted {
+		t.Fatalf("expected drift to be detected")
+	}
+	if report.Mismatches[0].ExpectedNodeType != NodeTypeUndefined {
+		t.Fatalf("expected ExpectedNodeType=Undefined, got %+v", report.Mismatches[0])
+	}
+}
+
+func TestDetectMismatchesAreSortedByKey(t *testing.T) {
+	schema := v1alpha1.AuthorizationSchema{
+		TypeDefinitions: []v1alpha1.TypeDefinition{
+			{Type: "zebra", Relations: map[string]v1alpha1.Relation{
+				"viewer": {Union: &v1alpha1.Union{Children: []v1alpha1.Relation{{}, {}}}},
+			}},
+			{Type: "apple", Relations: map[string]v1alpha1.Relation{
+				"viewer": {Union: &v1alpha1.Union{Children: []v1alpha1.Relation{{}, {}}}},
+			}},
+		},
+	}
+	observed := map[string]string{
+		"zebra#viewer": NodeTypeLeaf,
+		"apple#viewer": NodeTypeLeaf,
+	}
+
+	report := Detect(schema, observed)
+
+	if len(report.Mismatches) != 2 {
+		t.Fatalf("expected two mismatches, got %d", len(report.Mismatches))
+	}
+	if report.Mismatches[0].Type != "apple" || report.Mismatches[1].Type != "zebra" {
+		t.Fatalf("expected mismatches sorted by type#relation key, got %+v", report.Mismatches)
+	}
+}
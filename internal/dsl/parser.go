@@ -0,0 +1,238 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dsl transpiles OpenFGA's native modeling language into the
+// structured AuthorizationSchema consumed by the rest of the operator, so
+// AuthorizationModelSpec.DSL can be authored instead of the equivalent
+// nested Schema object. It supports the common subset of the grammar: type
+// declarations, direct/union/intersection/difference relation expressions
+// and tuple-to-userset ("X from Y") expressions.
+package dsl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+var (
+	typeHeaderRe     = regexp.MustCompile(`^type\s+([a-zA-Z][a-zA-Z0-9_]*)\s*$`)
+	relationRe       = regexp.MustCompile(`^define\s+([a-zA-Z][a-zA-Z0-9_]*)\s*:\s*(.+)$`)
+	directRe         = regexp.MustCompile(`^\[\s*([^\]]+)\s*\]$`)
+	tupleToUsersetRe = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9_]*)\s+from\s+([a-zA-Z][a-zA-Z0-9_]*)$`)
+)
+
+// Parse transpiles an OpenFGA DSL document into an AuthorizationSchema. It
+// returns parse errors rather than failing fast so the caller can surface
+// all of them at once in AuthorizationModelStatus.DSLParseErrors.
+func Parse(src string) (*v1alpha1.AuthorizationSchema, []error) {
+	var errs []error
+	schema := &v1alpha1.AuthorizationSchema{}
+
+	lines := strings.Split(src, "\n")
+	var currentType *v1alpha1.TypeDefinition
+	inRelations := false
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "model") || strings.HasPrefix(line, "schema ") {
+			continue
+		}
+
+		if m := typeHeaderRe.FindStringSubmatch(line); m != nil {
+			if currentType != nil {
+				schema.TypeDefinitions = append(schema.TypeDefinitions, *currentType)
+			}
+			currentType = &v1alpha1.TypeDefinition{Type: m[1]}
+			inRelations = false
+			continue
+		}
+
+		if line == "relations" {
+			inRelations = true
+			continue
+		}
+
+		if currentType == nil && relationRe.MatchString(line) {
+			errs = append(errs, fmt.Errorf("line %d: relation defined outside of a type block", i+1))
+			continue
+		}
+
+		if !inRelations {
+			continue
+		}
+
+		if currentType == nil {
+			errs = append(errs, fmt.Errorf("line %d: relation defined outside of a type block", i+1))
+			continue
+		}
+
+		m := relationRe.FindStringSubmatch(line)
+		if m == nil {
+			errs = append(errs, fmt.Errorf("line %d: expected \"define <relation>: <expression>\", got %q", i+1, line))
+			continue
+		}
+
+		relation, expr, err := parseExpression(m[1], m[2])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", i+1, err))
+			continue
+		}
+
+		if currentType.Relations == nil {
+			currentType.Relations = map[string]v1alpha1.Relation{}
+		}
+		currentType.Relations[relation] = expr
+	}
+
+	if currentType != nil {
+		schema.TypeDefinitions = append(schema.TypeDefinitions, *currentType)
+	}
+
+	if len(schema.TypeDefinitions) == 0 && len(errs) == 0 {
+		errs = append(errs, fmt.Errorf("no type definitions found"))
+	}
+
+	return schema, errs
+}
+
+// parseExpression parses the right-hand side of a "define <name>: <expr>"
+// statement. Operator precedence follows the OpenFGA DSL: "but not" binds
+// loosest, then "or", then "and", then the atoms.
+func parseExpression(name, expr string) (string, v1alpha1.Relation, error) {
+	expr = strings.TrimSpace(expr)
+
+	if parts := splitTopLevel(expr, " but not "); len(parts) == 2 {
+		base, err := parseAtomOrJoin(parts[0], " and ", " or ")
+		if err != nil {
+			return name, v1alpha1.Relation{}, err
+		}
+		sub, err := parseAtomOrJoin(parts[1], " and ", " or ")
+		if err != nil {
+			return name, v1alpha1.Relation{}, err
+		}
+		return name, v1alpha1.Relation{Difference: &v1alpha1.Difference{Base: base, Subtract: sub}}, nil
+	}
+
+	rel, err := parseAtomOrJoin(expr, " and ", " or ")
+	return name, rel, err
+}
+
+func parseAtomOrJoin(expr, andSep, orSep string) (v1alpha1.Relation, error) {
+	if parts := splitTopLevel(expr, orSep); len(parts) > 1 {
+		children := make([]v1alpha1.Relation, 0, len(parts))
+		for _, p := range parts {
+			child, err := parseAtomOrJoin(p, andSep, "")
+			if err != nil {
+				return v1alpha1.Relation{}, err
+			}
+			children = append(children, child)
+		}
+		return v1alpha1.Relation{Union: &v1alpha1.Union{Children: children}}, nil
+	}
+
+	if andSep != "" {
+		if parts := splitTopLevel(expr, andSep); len(parts) > 1 {
+			children := make([]v1alpha1.Relation, 0, len(parts))
+			for _, p := range parts {
+				child, err := parseAtom(p)
+				if err != nil {
+					return v1alpha1.Relation{}, err
+				}
+				children = append(children, child)
+			}
+			return v1alpha1.Relation{Intersection: &v1alpha1.Intersection{Children: children}}, nil
+		}
+	}
+
+	return parseAtom(expr)
+}
+
+// parseAtom parses a single relation term: a direct reference list
+// ("[user, group#member]"), a tuple-to-userset ("viewer from parent"), or a
+// bare computed userset reference ("owner").
+func parseAtom(expr string) (v1alpha1.Relation, error) {
+	expr = strings.TrimSpace(expr)
+
+	if m := directRe.FindStringSubmatch(expr); m != nil {
+		types := strings.Split(m[1], ",")
+		refs := make([]v1alpha1.Relation, 0, len(types))
+		for _, t := range types {
+			t = strings.TrimSpace(t)
+			wildcard := strings.HasSuffix(t, ":*")
+			t = strings.TrimSuffix(t, ":*")
+			typ, rel := t, ""
+			if idx := strings.Index(t, "#"); idx >= 0 {
+				typ, rel = t[:idx], t[idx+1:]
+			}
+			refs = append(refs, v1alpha1.Relation{This: &v1alpha1.RelationReference{
+				Type:     typ,
+				Relation: rel,
+				Wildcard: wildcard,
+			}})
+		}
+		if len(refs) == 1 {
+			return refs[0], nil
+		}
+		return v1alpha1.Relation{Union: &v1alpha1.Union{Children: refs}}, nil
+	}
+
+	if m := tupleToUsersetRe.FindStringSubmatch(expr); m != nil {
+		return v1alpha1.Relation{TupleToUserset: &v1alpha1.TupleToUserset{
+			TupleSet:        v1alpha1.TupleSet{Relation: m[2]},
+			ComputedUserset: v1alpha1.ComputedUserset{Relation: m[1]},
+		}}, nil
+	}
+
+	if expr == "" {
+		return v1alpha1.Relation{}, fmt.Errorf("empty relation expression")
+	}
+
+	return v1alpha1.Relation{ComputedUserset: &v1alpha1.ComputedUserset{Relation: expr}}, nil
+}
+
+// splitTopLevel splits expr on sep, ignoring occurrences inside [...] groups.
+// An empty sep never matches, so callers disabling a split level (e.g. a
+// union child that's already been separated on " or ") can pass "" safely.
+func splitTopLevel(expr, sep string) []string {
+	if sep == "" {
+		return []string{expr}
+	}
+
+	var parts []string
+	depth := 0
+	last := 0
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		if depth == 0 && strings.HasPrefix(expr[i:], sep) {
+			parts = append(parts, expr[last:i])
+			i += len(sep) - 1
+			last = i + 1
+		}
+	}
+	parts = append(parts, expr[last:])
+	return parts
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dsl
+
+import (
+	"testing"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+func findType(t *testing.T, schema *v1alpha1.AuthorizationSchema, name string) v1alpha1.TypeDefinition {
+	t.Helper()
+	for _, td := range schema.TypeDefinitions {
+		if td.Type == name {
+			return td
+		}
+	}
+	t.Fatalf("type %q not found in %+v", name, schema.TypeDefinitions)
+	return v1alpha1.TypeDefinition{}
+}
+
+func TestParseDirectAndUnionRelations(t *testing.T) {
+	src := `model
+  schema 1.1
+type user
+type document
+  relations
+    define owner: [user]
+    define viewer: [user] or owner
+`
+	schema, errs := Parse(src)
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	doc := findType(t, schema, "document")
+	owner := doc.Relations["owner"]
+	if owner.This == nil || owner.This.Type != "user" {
+		t.Fatalf("expected owner to be a direct reference to user, got %+v", owner)
+	}
+
+	viewer := doc.Relations["viewer"]
+	if viewer.Union == nil || len(viewer.Union.Children) != 2 {
+		t.Fatalf("expected viewer to be a 2-child union, got %+v", viewer)
+	}
+}
+
+func TestParseIntersectionAndDifference(t *testing.T) {
+	src := `type user
+type document
+  relations
+    define can_edit: owner and member
+    define can_view: viewer but not banned
+`
+	schema, errs := Parse(src)
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	doc := findType(t, schema, "document")
+	canEdit := doc.Relations["can_edit"]
+	if canEdit.Intersection == nil || len(canEdit.Intersection.Children) != 2 {
+		t.Fatalf("expected can_edit to be a 2-child intersection, got %+v", canEdit)
+	}
+
+	canView := doc.Relations["can_view"]
+	if canView.Difference == nil {
+		t.Fatalf("expected can_view to be a difference, got %+v", canView)
+	}
+	if canView.Difference.Base.ComputedUserset == nil || canView.Difference.Base.ComputedUserset.Relation != "viewer" {
+		t.Fatalf("expected difference base to be computed userset viewer, got %+v", canView.Difference.Base)
+	}
+}
+
+func TestParseTupleToUserset(t *testing.T) {
+	src := `type user
+type folder
+type document
+  relations
+    define viewer: viewer from parent
+`
+	schema, errs := Parse(src)
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	doc := findType(t, schema, "document")
+	viewer := doc.Relations["viewer"]
+	if viewer.TupleToUserset == nil {
+		t.Fatalf("expected viewer to be a tuple-to-userset, got %+v", viewer)
+	}
+	if viewer.TupleToUserset.TupleSet.Relation != "parent" {
+		t.Fatalf("expected tupleSet relation parent, got %q", viewer.TupleToUserset.TupleSet.Relation)
+	}
+	if viewer.TupleToUserset.ComputedUserset.Relation != "viewer" {
+		t.Fatalf("expected computedUserset relation viewer, got %q", viewer.TupleToUserset.ComputedUserset.Relation)
+	}
+}
+
+func TestParseWildcardAndUsersetReference(t *testing.T) {
+	src := `type user
+type group
+type document
+  relations
+    define viewer: [user:*, group#member]
+`
+	schema, errs := Parse(src)
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	doc := findType(t, schema, "document")
+	viewer := doc.Relations["viewer"]
+	if viewer.Union == nil || len(viewer.Union.Children) != 2 {
+		t.Fatalf("expected viewer to be a 2-child union, got %+v", viewer)
+	}
+	wildcard := viewer.Union.Children[0]
+	if wildcard.This == nil || !wildcard.This.Wildcard || wildcard.This.Type != "user" {
+		t.Fatalf("expected first child to be a user wildcard, got %+v", wildcard)
+	}
+	userset := viewer.Union.Children[1]
+	if userset.This == nil || userset.This.Type != "group" || userset.This.Relation != "member" {
+		t.Fatalf("expected second child to reference group#member, got %+v", userset)
+	}
+}
+
+func TestParseReportsRelationOutsideTypeBlock(t *testing.T) {
+	_, errs := Parse("define viewer: [user]\n")
+	if len(errs) == 0 {
+		t.Fatalf("expected an error for a relation defined outside a type block")
+	}
+}
+
+func TestParseReportsMalformedRelationLine(t *testing.T) {
+	src := `type document
+  relations
+    not a valid relation line
+`
+	_, errs := Parse(src)
+	if len(errs) == 0 {
+		t.Fatalf("expected an error for a malformed relation line")
+	}
+}
+
+func TestParseReportsEmptyDocument(t *testing.T) {
+	_, errs := Parse("\n\n")
+	if len(errs) == 0 {
+		t.Fatalf("expected an error for a document with no type definitions")
+	}
+}
+
+func TestParseIgnoresCommentsAndBlankLines(t *testing.T) {
+	src := `# a leading comment
+model
+  schema 1.1
+
+type user
+`
+	schema, errs := Parse(src)
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+	if len(schema.TypeDefinitions) != 1 || schema.TypeDefinitions[0].Type != "user" {
+		t.Fatalf("expected a single user type, got %+v", schema.TypeDefinitions)
+	}
+}
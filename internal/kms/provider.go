@@ -0,0 +1,58 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kms implements envelope encryption for backup snapshots: a fresh
+// data encryption key (DEK) is generated per backup, used to encrypt the
+// snapshot directly, and wrapped via the provider selected on
+// EncryptionConfig. The wrapped DEK is persisted alongside the ciphertext so
+// restore can reverse the process.
+package kms
+
+import "context"
+
+// EventReasonUnwrapFailed is the Kubernetes event reason emitted when a KMS
+// provider fails to unwrap a DEK, so operators can alert on KMS outages.
+const EventReasonUnwrapFailed = "KMSUnwrapFailed"
+
+// EventReasonProviderUnavailable is the Kubernetes event reason emitted when
+// a KMS provider cannot be reached to generate a DEK or report its active
+// key version - distinct from EventReasonUnwrapFailed, which is specific to
+// an already-wrapped key that fails to unwrap.
+const EventReasonProviderUnavailable = "KMSProviderUnavailable"
+
+// WrappedKey is the small manifest persisted alongside a backup's ciphertext.
+type WrappedKey struct {
+	// Provider is the EncryptionConfig.Provider value used to wrap Ciphertext
+	Provider string
+
+	// KeyVersion identifies the wrapping key version, as reported by the provider
+	KeyVersion string
+
+	// Ciphertext is the DEK, wrapped by the provider's key
+	Ciphertext []byte
+}
+
+// Provider wraps and unwraps data encryption keys using a KMS, HSM or static secret.
+type Provider interface {
+	// GenerateDEK returns a fresh plaintext data encryption key and its wrapped form
+	GenerateDEK(ctx context.Context) (plaintextDEK []byte, wrapped WrappedKey, err error)
+
+	// Unwrap decrypts a previously wrapped DEK back to its plaintext form
+	Unwrap(ctx context.Context, wrapped WrappedKey) (plaintextDEK []byte, err error)
+
+	// KeyVersion returns the identifier of the provider's current active wrapping key
+	KeyVersion(ctx context.Context) (string, error)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestNewSecretProviderRejectsWrongKeyLength(t *testing.T) {
+	if _, err := NewSecretProvider(make([]byte, 16)); err == nil {
+		t.Fatalf("expected an error for a 16-byte key")
+	}
+}
+
+func TestSecretProviderGenerateAndUnwrapRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewSecretProvider(bytes.Repeat([]byte{0x42}, 32))
+	if err != nil {
+		t.Fatalf("NewSecretProvider returned error: %v", err)
+	}
+
+	dek, wrapped, err := provider.GenerateDEK(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDEK returned error: %v", err)
+	}
+	if len(dek) != 32 {
+		t.Fatalf("expected a 32-byte DEK, got %d bytes", len(dek))
+	}
+	if wrapped.Provider != "secret" {
+		t.Fatalf("expected wrapped.Provider \"secret\", got %q", wrapped.Provider)
+	}
+
+	unwrapped, err := provider.Unwrap(ctx, wrapped)
+	if err != nil {
+		t.Fatalf("Unwrap returned error: %v", err)
+	}
+	if !bytes.Equal(dek, unwrapped) {
+		t.Fatalf("expected unwrapped DEK to match the original")
+	}
+}
+
+func TestSecretProviderKeyVersionStableAndRotates(t *testing.T) {
+	ctx := context.Background()
+	a, err := NewSecretProvider(bytes.Repeat([]byte{0x01}, 32))
+	if err != nil {
+		t.Fatalf("NewSecretProvider returned error: %v", err)
+	}
+	b, err := NewSecretProvider(bytes.Repeat([]byte{0x01}, 32))
+	if err != nil {
+		t.Fatalf("NewSecretProvider returned error: %v", err)
+	}
+	c, err := NewSecretProvider(bytes.Repeat([]byte{0x02}, 32))
+	if err != nil {
+		t.Fatalf("NewSecretProvider returned error: %v", err)
+	}
+
+	va, _ := a.KeyVersion(ctx)
+	vb, _ := b.KeyVersion(ctx)
+	vc, _ := c.KeyVersion(ctx)
+
+	if va != vb {
+		t.Fatalf("expected identical keys to report the same version, got %q and %q", va, vb)
+	}
+	if va == vc {
+		t.Fatalf("expected different keys to report different versions, both got %q", va)
+	}
+}
+
+func TestSecretProviderUnwrapRejectsTamperedCiphertext(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewSecretProvider(bytes.Repeat([]byte{0x42}, 32))
+	if err != nil {
+		t.Fatalf("NewSecretProvider returned error: %v", err)
+	}
+
+	_, wrapped, err := provider.GenerateDEK(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDEK returned error: %v", err)
+	}
+	wrapped.Ciphertext[len(wrapped.Ciphertext)-1] ^= 0xFF
+
+	if _, err := provider.Unwrap(ctx, wrapped); err == nil {
+		t.Fatalf("expected an error unwrapping tampered ciphertext")
+	}
+}
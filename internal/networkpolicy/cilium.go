@@ -0,0 +1,231 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package networkpolicy translates the operator's vendor-neutral
+// NetworkPolicyConfig into concrete Kubernetes NetworkPolicy or Cilium
+// CiliumNetworkPolicy/CiliumClusterwideNetworkPolicy objects.
+package networkpolicy
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+const (
+	ciliumNetworkPolicyKind            = "CiliumNetworkPolicy"
+	ciliumClusterwideNetworkPolicyKind = "CiliumClusterwideNetworkPolicy"
+	ciliumAPIVersion                   = "cilium.io/v2"
+)
+
+// CiliumNetworkPolicy builds the unstructured CiliumNetworkPolicy (or
+// CiliumClusterwideNetworkPolicy when cfg.Scope is "cluster") for the given
+// NetworkPolicyConfig. selectorLabels must be the managed workload's own pod
+// labels (e.g. the Deployment's spec.selector.matchLabels) so the generated
+// policy scopes endpointSelector to that workload rather than every endpoint
+// in the namespace/cluster. Callers are expected to have already verified
+// the CRD is registered in the cluster before creating/updating the object.
+func CiliumNetworkPolicy(name, namespace string, cfg *v1alpha1.NetworkPolicyConfig, selectorLabels map[string]string, owner metav1.OwnerReference) *unstructured.Unstructured {
+	kind := ciliumNetworkPolicyKind
+	clusterScoped := cfg.Scope == "cluster"
+	if clusterScoped {
+		kind = ciliumClusterwideNetworkPolicyKind
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(ciliumAPIVersion)
+	obj.SetKind(kind)
+	obj.SetName(name)
+	if !clusterScoped {
+		obj.SetNamespace(namespace)
+	}
+	obj.SetLabels(cfg.CiliumLabels)
+	obj.SetOwnerReferences([]metav1.OwnerReference{owner})
+
+	spec := map[string]interface{}{
+		"endpointSelector": map[string]interface{}{
+			"matchLabels": stringMap(selectorLabels),
+		},
+	}
+	if ingress := translateRules(cfg.AllowedIngress, true); len(ingress) > 0 {
+		spec["ingress"] = ingress
+	}
+	if egress := translateRules(cfg.AllowedEgress, false); len(egress) > 0 {
+		spec["egress"] = egress
+	}
+
+	obj.Object["spec"] = spec
+	return obj
+}
+
+func translateRules(rules []v1alpha1.NetworkPolicyRule, ingress bool) []interface{} {
+	out := make([]interface{}, 0, len(rules))
+	for _, rule := range rules {
+		entry := map[string]interface{}{}
+
+		peers := rule.From
+		if !ingress {
+			peers = rule.To
+		}
+		if selectors := translatePeers(peers); len(selectors) > 0 {
+			if ingress {
+				entry["fromEndpoints"] = selectors
+			} else {
+				entry["toEndpoints"] = selectors
+			}
+		}
+		if cidrs := translateCIDRs(peers); len(cidrs) > 0 {
+			if ingress {
+				entry["fromCIDR"] = cidrs
+			} else {
+				entry["toCIDR"] = cidrs
+			}
+		}
+
+		if rules := translateL7(rule); rules != nil {
+			entry["toPorts"] = rules
+		} else if ports := translatePorts(rule.Ports); len(ports) > 0 {
+			entry["toPorts"] = []interface{}{
+				map[string]interface{}{"ports": ports},
+			}
+		}
+
+		out = append(out, entry)
+	}
+	return out
+}
+
+func translatePeers(peers []v1alpha1.NetworkPolicyPeer) []interface{} {
+	out := make([]interface{}, 0, len(peers))
+	for _, peer := range peers {
+		if peer.PodSelector == nil && peer.NamespaceSelector == nil {
+			continue
+		}
+		selector := map[string]interface{}{"matchLabels": map[string]interface{}{}}
+		if peer.PodSelector != nil {
+			for k, v := range peer.PodSelector.MatchLabels {
+				selector["matchLabels"].(map[string]interface{})[k] = v
+			}
+		}
+		if peer.NamespaceSelector != nil {
+			for k, v := range peer.NamespaceSelector.MatchLabels {
+				selector["matchLabels"].(map[string]interface{})["k8s:io.kubernetes.pod.namespace/"+k] = v
+			}
+		}
+		out = append(out, map[string]interface{}{"matchLabels": selector["matchLabels"]})
+	}
+	return out
+}
+
+func translateCIDRs(peers []v1alpha1.NetworkPolicyPeer) []interface{} {
+	out := make([]interface{}, 0, len(peers))
+	for _, peer := range peers {
+		if peer.IPBlock == nil {
+			continue
+		}
+		out = append(out, peer.IPBlock.CIDR)
+	}
+	return out
+}
+
+func translatePorts(ports []v1alpha1.NetworkPolicyPort) []interface{} {
+	out := make([]interface{}, 0, len(ports))
+	for _, p := range ports {
+		entry := map[string]interface{}{}
+		if p.Port != nil {
+			entry["port"] = int64(*p.Port)
+		}
+		if p.Protocol != nil {
+			entry["protocol"] = string(*p.Protocol)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// translateL7 builds Cilium's toPorts[].rules shape for any rule carrying
+// Cilium HTTP/gRPC matchers, layering them over the rule's plain ports.
+func translateL7(rule v1alpha1.NetworkPolicyRule) []interface{} {
+	if rule.Cilium == nil || (len(rule.Cilium.HTTP) == 0 && len(rule.Cilium.GRPC) == 0) {
+		return nil
+	}
+
+	l7Rules := map[string]interface{}{}
+
+	if len(rule.Cilium.HTTP) > 0 {
+		http := make([]interface{}, 0, len(rule.Cilium.HTTP))
+		for _, h := range rule.Cilium.HTTP {
+			entry := map[string]interface{}{}
+			if h.Method != "" {
+				entry["method"] = h.Method
+			}
+			if h.Path != "" {
+				entry["path"] = h.Path
+			}
+			if len(h.Headers) > 0 {
+				entry["headers"] = toInterfaceSlice(h.Headers)
+			}
+			http = append(http, entry)
+		}
+		l7Rules["http"] = http
+	}
+
+	if len(rule.Cilium.GRPC) > 0 {
+		grpc := make([]interface{}, 0, len(rule.Cilium.GRPC))
+		for _, g := range rule.Cilium.GRPC {
+			path := "/" + g.Service + "/"
+			if g.Method != "" {
+				path += g.Method
+			}
+			grpc = append(grpc, map[string]interface{}{
+				"path":   path,
+				"method": "POST",
+			})
+		}
+		l7Rules["http"] = append(toSlice(l7Rules["http"]), grpc...)
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"ports": translatePorts(rule.Ports),
+			"rules": l7Rules,
+		},
+	}
+}
+
+func toSlice(v interface{}) []interface{} {
+	if v == nil {
+		return nil
+	}
+	return v.([]interface{})
+}
+
+func toInterfaceSlice(in []string) []interface{} {
+	out := make([]interface{}, len(in))
+	for i, v := range in {
+		out[i] = v
+	}
+	return out
+}
+
+func stringMap(in map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jralmaraz/authcore-openfga-operator/api/v1alpha1"
+)
+
+func TestTranslatePeersNamespaceSelectorOnly(t *testing.T) {
+	peers := []v1alpha1.NetworkPolicyPeer{
+		{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"team": "platform"},
+			},
+		},
+	}
+
+	out := translatePeers(peers)
+	if len(out) != 1 {
+		t.Fatalf("expected 1 selector, got %d", len(out))
+	}
+
+	entry, ok := out[0].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected map[string]interface{}, got %T", out[0])
+	}
+	labels, ok := entry["matchLabels"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected matchLabels to be a map, got %T", entry["matchLabels"])
+	}
+	if got := labels["k8s:io.kubernetes.pod.namespace/team"]; got != "platform" {
+		t.Fatalf("expected namespace label to be translated, got %v", labels)
+	}
+}
+
+func TestTranslatePeersPodAndNamespaceSelector(t *testing.T) {
+	peers := []v1alpha1.NetworkPolicyPeer{
+		{
+			PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "openfga"}},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "platform"}},
+		},
+	}
+
+	out := translatePeers(peers)
+	entry := out[0].(map[string]interface{})
+	labels := entry["matchLabels"].(map[string]interface{})
+	if labels["app"] != "openfga" {
+		t.Fatalf("expected pod label to be preserved, got %v", labels)
+	}
+	if labels["k8s:io.kubernetes.pod.namespace/team"] != "platform" {
+		t.Fatalf("expected namespace label to be translated, got %v", labels)
+	}
+}
+
+func TestTranslatePeersSkipsEmptyPeer(t *testing.T) {
+	peers := []v1alpha1.NetworkPolicyPeer{{}}
+	if out := translatePeers(peers); len(out) != 0 {
+		t.Fatalf("expected empty peer to be skipped, got %d entries", len(out))
+	}
+}
+
+func TestCiliumNetworkPolicyScopesEndpointSelectorToWorkload(t *testing.T) {
+	cfg := &v1alpha1.NetworkPolicyConfig{}
+	selectorLabels := map[string]string{"app.kubernetes.io/name": "openfga", "app.kubernetes.io/instance": "my-server"}
+
+	obj := CiliumNetworkPolicy("my-server-netpol", "default", cfg, selectorLabels, metav1.OwnerReference{})
+
+	spec, ok := obj.Object["spec"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected spec to be a map, got %T", obj.Object["spec"])
+	}
+	endpointSelector, ok := spec["endpointSelector"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected endpointSelector to be a map, got %T", spec["endpointSelector"])
+	}
+	matchLabels, ok := endpointSelector["matchLabels"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected matchLabels to be a map, got %T", endpointSelector["matchLabels"])
+	}
+	if matchLabels["app.kubernetes.io/name"] != "openfga" || matchLabels["app.kubernetes.io/instance"] != "my-server" {
+		t.Fatalf("expected endpointSelector to scope to the workload's own labels, got %v", matchLabels)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package openfga defines the operator's view of the OpenFGA HTTP/gRPC API.
+// Reconcilers depend only on the Client interface so they can be unit tested
+// without a live server and so the concrete transport - in-cluster HTTP
+// against an OpenFGAServer, or an external endpoint resolved from an
+// OpenFGAServerConnection - can be swapped per store without touching
+// reconciler logic.
+package openfga
+
+import "context"
+
+// Tuple is a single OpenFGA relationship tuple: user is relation of object
+type Tuple struct {
+	User      string
+	Relation  string
+	Object    string
+	Condition *TupleCondition
+}
+
+// TupleCondition pins a tuple to a named ABAC condition and its context,
+// mirroring v1alpha1.Condition evaluated at Check/Write time
+type TupleCondition struct {
+	Name    string
+	Context map[string]interface{}
+}
+
+// Model is an OpenFGA authorization model as returned by the API: its
+// server-assigned ID and the raw type-definitions document used to recreate it
+type Model struct {
+	ID       string
+	Document []byte
+}
+
+// NodeType is the shape of a single UsersetTreeNode, as returned by Expand.
+// Drift detection compares NodeType across the tree while ignoring the
+// underlying tuple set contents
+type NodeType string
+
+const (
+	NodeLeaf           NodeType = "Leaf"
+	NodeUnion          NodeType = "Union"
+	NodeIntersection   NodeType = "Intersection"
+	NodeDifference     NodeType = "Difference"
+	NodeTupleToUserset NodeType = "TupleToUserset"
+)
+
+// UsersetTreeNode is one node of the tree an Expand call returns for a
+// single type#relation pair. Users is only populated on a NodeLeaf node, and
+// holds the leaf's direct users/usersets/typed wildcards, e.g.
+// "user:anne" or "team:acme#member"
+type UsersetTreeNode struct {
+	NodeType NodeType
+	Children []UsersetTreeNode
+	Users    []string
+}
+
+// Client is the operator's interface to a single OpenFGA server's HTTP/gRPC
+// API. A concrete implementation is built per store from its resolved
+// ServerRef or OpenFGAServerConnection context
+type Client interface {
+	// StreamTuples reads every tuple in storeID in pages, invoking fn once per page
+	StreamTuples(ctx context.Context, storeID string, pageSize int32, fn func([]Tuple) error) error
+
+	// StreamAuthorizationModels reads every authorization model ever written
+	// to storeID, oldest first, invoking fn once per model
+	StreamAuthorizationModels(ctx context.Context, storeID string, fn func(Model) error) error
+
+	// WriteTuples writes and deletes tuples in a single transaction
+	WriteTuples(ctx context.Context, storeID string, writes, deletes []Tuple) error
+
+	// WriteAuthorizationModel writes a new, immutable authorization model and returns its assigned ID
+	WriteAuthorizationModel(ctx context.Context, storeID string, document []byte) (modelID string, err error)
+
+	// Check evaluates whether user has relation on object under modelID
+	Check(ctx context.Context, storeID, modelID, user, relation, object string, context map[string]interface{}) (allowed bool, err error)
+
+	// Expand returns the userset tree for a single type#relation pair on object
+	Expand(ctx context.Context, storeID, modelID, object, relation string) (UsersetTreeNode, error)
+
+	// ListObjects lists the objects of objectType that user has relation on
+	ListObjects(ctx context.Context, storeID, modelID, user, relation, objectType string) ([]string, error)
+
+	// ListUsers lists the users - including typed wildcards and usersets -
+	// that have relation on object, optionally restricted to userFilters
+	ListUsers(ctx context.Context, storeID, modelID, object, relation string, userFilters []string) ([]string, error)
+}